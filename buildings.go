@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Kartverket FKB-Bygning WFS. Footprints are requested as GeoJSON in CRS84
+// (lon, lat) so the axis order is unambiguous.
+const (
+	fkbBygningURL      = "https://wfs.geonorge.no/skwms1/wfs.fkb-bygning"
+	fkbBygningType     = "app:Bygning"
+	buildingsCacheTTL  = 24 * time.Hour
+	buildingSearchM    = 25.0
+	buildingMaxCorners = 8
+	buildingMaxCount   = 3
+
+	// buildingPointWorkers bounds the sample points checked at once across
+	// all buildings; each point check makes several NVE requests.
+	buildingPointWorkers = 4
+)
+
+type buildingCollection struct {
+	Features []buildingFeature `json:"features"`
+}
+
+type buildingFeature struct {
+	Properties map[string]any `json:"properties"`
+	Geometry   struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// footprint is a building's exterior ring in the local frame, in meters.
+type footprint struct {
+	id   string
+	ring [][2]float64
+}
+
+// getBuildings returns footprints of buildings containing the address point,
+// or if none does, the nearest building within buildingSearchM.
+func getBuildings(ctx context.Context, cache *Cache, frame localFrame) ([]footprint, error) {
+	minLat, minLon := frame.toLatLon(-buildingSearchM, -buildingSearchM)
+	maxLat, maxLon := frame.toLatLon(buildingSearchM, buildingSearchM)
+
+	params := url.Values{
+		"service":      {"WFS"},
+		"version":      {"2.0.0"},
+		"request":      {"GetFeature"},
+		"typeNames":    {fkbBygningType},
+		"srsName":      {"urn:ogc:def:crs:OGC:1.3:CRS84"},
+		"bbox":         {fmt.Sprintf("%f,%f,%f,%f,urn:ogc:def:crs:OGC:1.3:CRS84", minLon, minLat, maxLon, maxLat)},
+		"outputFormat": {"application/json"},
+	}
+	data, err := cachedGet(ctx, cache, fkbBygningURL+"?"+params.Encode(), buildingsCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("fkb-bygning: %w", err)
+	}
+
+	var result buildingCollection
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("fkb-bygning decode: %w", err)
+	}
+
+	var containing []footprint
+	var nearest *footprint
+	nearestDist := math.Inf(1)
+	for _, f := range result.Features {
+		ring := exteriorRing(f)
+		if len(ring) < 3 {
+			continue
+		}
+		fp := footprint{
+			id:   attrString(f.Properties, "bygningsnummer", "bygningsnr"),
+			ring: make([][2]float64, len(ring)),
+		}
+		if fp.id == "" {
+			if n := attrIntVal(f.Properties, "bygningsnummer", "bygningsnr"); n != 0 {
+				fp.id = fmt.Sprint(n)
+			}
+		}
+		for i, c := range ring {
+			e, n := frame.toMeters(c[1], c[0])
+			fp.ring[i] = [2]float64{e, n}
+		}
+
+		if containsOrigin(fp.ring) {
+			containing = append(containing, fp)
+			continue
+		}
+		if d := ringDistance(fp.ring); d < nearestDist && d <= buildingSearchM {
+			nearestDist = d
+			nearest = &fp
+		}
+	}
+
+	if len(containing) == 0 && nearest != nil {
+		containing = append(containing, *nearest)
+	}
+	if len(containing) > buildingMaxCount {
+		containing = containing[:buildingMaxCount]
+	}
+	return containing, nil
+}
+
+// exteriorRing returns the outer ring of a Polygon, or of the first polygon
+// of a MultiPolygon, as [lon, lat] pairs.
+func exteriorRing(f buildingFeature) [][2]float64 {
+	switch f.Geometry.Type {
+	case "Polygon":
+		var rings [][][2]float64
+		if json.Unmarshal(f.Geometry.Coordinates, &rings) == nil && len(rings) > 0 {
+			return rings[0]
+		}
+	case "MultiPolygon":
+		var polys [][][][2]float64
+		if json.Unmarshal(f.Geometry.Coordinates, &polys) == nil && len(polys) > 0 && len(polys[0]) > 0 {
+			return polys[0][0]
+		}
+	}
+	return nil
+}
+
+// containsOrigin reports whether the ring contains the origin (ray casting).
+func containsOrigin(ring [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > 0) != (b[1] > 0) && 0 < (b[0]-a[0])*(0-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ringDistance returns the distance from the origin to the ring's boundary.
+func ringDistance(ring [][2]float64) float64 {
+	best := math.Inf(1)
+	for i := 1; i < len(ring); i++ {
+		px, py := closestOnSegment(ring[i-1][0], ring[i-1][1], ring[i][0], ring[i][1])
+		best = min(best, math.Hypot(px, py))
+	}
+	return best
+}
+
+// samplePoints returns up to buildingMaxCorners corners, evenly spread
+// around the ring, followed by the vertex centroid.
+func (fp footprint) samplePoints() []BuildingPoint {
+	ring := fp.ring
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+
+	step := max(1, (len(ring)+buildingMaxCorners-1)/buildingMaxCorners)
+	var points []BuildingPoint
+	var ce, cn float64
+	for i := 0; i < len(ring); i += step {
+		points = append(points, BuildingPoint{Kind: "corner", east: ring[i][0], north: ring[i][1]})
+	}
+	for _, c := range ring {
+		ce += c[0]
+		cn += c[1]
+	}
+	n := float64(len(ring))
+	return append(points, BuildingPoint{Kind: "centroid", east: ce / n, north: cn / n})
+}
+
+// assessBuildings runs the location-only NVE checks and an elevation lookup
+// at the corners and centroid of each building footprint at the address,
+// and reports the worst case per hazard and the lowest ground elevation.
+func assessBuildings(ctx context.Context, cache *Cache, addr Address, uncertainty float64, loc localizer) ([]BuildingAssessment, error) {
+	frame := newLocalFrame(addr.Latitude, addr.Longitude)
+	footprints, err := getBuildings(ctx, cache, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	buildings := make([]BuildingAssessment, len(footprints))
+	workers := make(chan struct{}, buildingPointWorkers)
+	var wg sync.WaitGroup
+	for i, fp := range footprints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buildings[i] = assessBuilding(ctx, cache, addr.Kommunenummer, frame, fp, uncertainty, workers, loc)
+		}()
+	}
+	wg.Wait()
+	return buildings, nil
+}
+
+func assessBuilding(ctx context.Context, cache *Cache, knr string, frame localFrame, fp footprint, uncertainty float64, workers chan struct{}, loc localizer) BuildingAssessment {
+	b := BuildingAssessment{ID: fp.id, Points: fp.samplePoints()}
+
+	for _, c := range fp.ring {
+		lat, lon := frame.toLatLon(c[0], c[1])
+		b.Footprint = append(b.Footprint, [2]float64{lat, lon})
+	}
+
+	coords := make([][2]float64, len(b.Points))
+	for i := range b.Points {
+		p := &b.Points[i]
+		p.Latitude, p.Longitude = frame.toLatLon(p.east, p.north)
+		coords[i] = [2]float64{p.Latitude, p.Longitude}
+	}
+
+	// A check whose layers do not touch the footprint gives the same result
+	// at every point, so it runs at the centroid only. The centroid is the
+	// last sample point.
+	checks := pointChecks()
+	hits := footprintHits(ctx, cache, b.Footprint, checks)
+	var wg sync.WaitGroup
+	results := make([][]HazardResult, len(b.Points))
+	for i, p := range b.Points {
+		var run []pointCheck
+		for _, c := range checks {
+			if i == len(b.Points)-1 || c.touches(hits) {
+				run = append(run, c)
+			}
+		}
+		if len(run) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			results[i] = checkPointHazards(ctx, cache, run, p.Latitude, p.Longitude, loc)
+		}()
+	}
+
+	elevations, err := getElevations(ctx, cache, coords)
+	if err != nil {
+		log.Printf("building elevation error: %v", err)
+	}
+	wg.Wait()
+
+	worst := make(map[string]int) // hazard ID -> index in b.Hazards
+	for i, hazards := range results {
+		p := &b.Points[i]
+		if elevations != nil {
+			p.Elevation = elevations[i]
+			if p.Elevation != nil && (b.LowestElevation == nil || *p.Elevation < *b.LowestElevation) {
+				b.LowestElevation = p.Elevation
+			}
+		}
+		for _, h := range hazards {
+			p.MaxScore = max(p.MaxScore, h.Score)
+			j, seen := worst[h.ID]
+			switch {
+			case !seen:
+				worst[h.ID] = len(b.Hazards)
+				b.Hazards = append(b.Hazards, h)
+			case b.Hazards[j].Error != "" && h.Error == "", h.Error == "" && h.Score > b.Hazards[j].Score:
+				b.Hazards[j] = h
+			}
+		}
+	}
+
+	b.OverallScore, b.OverallLevel, _ = calculateRisk(b.Hazards, b.LowestElevation, uncertainty, knr, loc)
+	return b
+}
+
+// pointCheck is an NVE map check that depends only on location, with the
+// zone layers whose features it reports. Mapping coverage layers are left
+// out: where no zone touches a building, the centroid's coverage stands for
+// the whole building.
+type pointCheck struct {
+	layers []nveService
+	run    func(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) HazardResult
+}
+
+func pointChecks() []pointCheck {
+	var floodLayers, zoneLayers []nveService
+	for _, fl := range floodLevels {
+		floodLayers = append(floodLayers, fl.svc)
+	}
+	for _, zl := range hazardZoneLevels {
+		zoneLayers = append(zoneLayers, zl.svc)
+	}
+
+	checks := []pointCheck{
+		{floodLayers, func(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) HazardResult {
+			h, _ := checkFloodZones(ctx, cache, lat, lon, loc)
+			return h
+		}},
+		{[]nveService{svcQuickClayDetail, svcQuickClayOverview}, checkQuickClay},
+		{zoneLayers, checkHazardZones},
+	}
+	for _, c := range awarenessChecks {
+		checks = append(checks, pointCheck{[]nveService{c.svc}, c.check})
+	}
+	return checks
+}
+
+// touches reports whether any of the check's layers intersects the
+// footprint. A nil hits means the footprint could not be checked.
+func (c pointCheck) touches(hits map[nveService]bool) bool {
+	if hits == nil {
+		return true
+	}
+	for _, svc := range c.layers {
+		if hits[svc] {
+			return true
+		}
+	}
+	return false
+}
+
+// footprintHits finds the layers of the checks that intersect a footprint,
+// with one identify request per MapServer. It returns nil if any request
+// fails, so that every point is checked.
+func footprintHits(ctx context.Context, cache *Cache, ring [][2]float64, checks []pointCheck) map[nveService]bool {
+	services := make(map[string][]nveService) // by MapServer
+	for _, c := range checks {
+		for _, svc := range c.layers {
+			services[svc.BaseURL] = append(services[svc.BaseURL], svc)
+		}
+	}
+
+	var mu sync.Mutex
+	var failed bool
+	hits := make(map[nveService]bool)
+	var wg sync.WaitGroup
+	for baseURL, svcs := range services {
+		ids := make([]int, len(svcs))
+		for i, svc := range svcs {
+			ids[i] = svc.Layer
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			byLayer, err := identifyNVEPolygon(ctx, cache, baseURL, ids, ring)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("building footprint identify: %v", err)
+				failed = true
+				return
+			}
+			for _, svc := range svcs {
+				if len(byLayer[svc.Layer]) > 0 {
+					hits[svc] = true
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if failed {
+		return nil
+	}
+	return hits
+}
+
+// checkPointHazards runs the given checks at a point, in parallel.
+func checkPointHazards(ctx context.Context, cache *Cache, checks []pointCheck, lat, lon float64, loc localizer) []HazardResult {
+	results := make([]HazardResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx, cache, lat, lon, loc)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// buildingsNote summarizes building assessments for the risk summary.
+func buildingsNote(buildings []BuildingAssessment, addressScore int, loc localizer) string {
+	var worse []string
+	for _, b := range buildings {
+		if b.OverallScore > addressScore {
+			name := loc.T("bygningen")
+			if b.ID != "" {
+				name = loc.Sprintf("bygning %s", b.ID)
+			}
+			worse = append(worse, fmt.Sprintf("%s (score %d)", name, b.OverallScore))
+		}
+	}
+	if len(worse) == 0 {
+		return ""
+	}
+	return loc.Sprintf("Deler av %s ligger mer utsatt enn adressepunktet.", loc.Join(worse))
+}
@@ -7,6 +7,7 @@ import (
 
 type cacheEntry struct {
 	value     []byte
+	storedAt  time.Time
 	expiresAt time.Time
 }
 
@@ -30,21 +31,29 @@ func NewCache() *Cache {
 
 // Get returns the cached value if present and not expired.
 func (c *Cache) Get(key string) ([]byte, bool) {
+	value, _, ok := c.GetStored(key)
+	return value, ok
+}
+
+// GetStored is like Get but also returns when the value was stored.
+func (c *Cache) GetStored(key string) ([]byte, time.Time, bool) {
 	c.mu.RLock()
 	entry, ok := c.entries[key]
 	c.mu.RUnlock()
 	if !ok || time.Now().After(entry.expiresAt) {
-		return nil, false
+		return nil, time.Time{}, false
 	}
-	return entry.value, true
+	return entry.value, entry.storedAt, true
 }
 
 // Set stores a value with the given TTL.
 func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
 	c.mu.Lock()
 	c.entries[key] = cacheEntry{
 		value:     value,
-		expiresAt: time.Now().Add(ttl),
+		storedAt:  now,
+		expiresAt: now.Add(ttl),
 	}
 	c.mu.Unlock()
 }
@@ -0,0 +1,158 @@
+package main
+
+// climateScenario describes a future horizon used to project flood and
+// storm surge risk beyond today's hazard maps.
+type climateScenario struct {
+	Name string
+	// SeaLevelRise is the projected relative sea level rise in meters
+	// (including land uplift), used against elevation for storm surge.
+	SeaLevelRise float64
+	// FloodShare is the share of NVE's klimapåslag applied at this horizon.
+	// NVE's surcharges are given for the end of the century.
+	FloodShare float64
+}
+
+// climateScenarios are the supported values of the scenario query parameter.
+// Sea level rise figures are approximate national medians from Kartverket's
+// projections (high emission scenario); local values vary with land uplift,
+// by more than half a meter between inner fjords and the outer coast. The
+// projection carries a note saying so.
+var climateScenarios = map[string]climateScenario{
+	"2050": {Name: "2050", SeaLevelRise: 0.15, FloodShare: 0.5},
+	"2100": {Name: "2100", SeaLevelRise: 0.55, FloodShare: 1.0},
+}
+
+// parseScenario resolves the scenario query parameter. An empty value or
+// "present" means no projection.
+func parseScenario(s string) (*climateScenario, bool) {
+	if s == "" || s == "present" {
+		return nil, true
+	}
+	sc, ok := climateScenarios[s]
+	if !ok {
+		return nil, false
+	}
+	return &sc, true
+}
+
+// floodClimateSurcharge returns NVE's recommended klimapåslag (percent increase
+// in flood discharge) for a municipality. This is a simplified county-level
+// approximation: rain-dominated western and southern regions get 40%,
+// everywhere else 20%.
+func floodClimateSurcharge(knr string) int {
+	if len(knr) < 2 {
+		return 20
+	}
+	switch knr[:2] {
+	case "11", // Rogaland
+		"15", // Møre og Romsdal
+		"42", // Agder
+		"46": // Vestland
+		return 40
+	default:
+		return 20
+	}
+}
+
+// projectFloodZones estimates the flood zone score under a climate scenario.
+// Each 20% of klimapåslag moves the address roughly one step towards a more
+// frequent return period (e.g. today's 200-year zone behaves like a 100-year
+// zone). Fractional steps are interpolated between neighbouring levels.
+// matched is the index into floodLevels of today's worst match, or -1.
+func projectFloodZones(sc *climateScenario, knr string, matched int, loc localizer) *HazardProjection {
+	pct := float64(floodClimateSurcharge(knr)) * sc.FloodShare
+	p := &HazardProjection{Scenario: sc.Name}
+
+	if matched < 0 {
+		p.Description = loc.T("Ikke i kartlagt flomsone")
+		p.Details = loc.Sprintf("Flomsonekartene viser dagens situasjon. Med %.0f %% klimapåslag kan flomsonen utvides, men punktet er ikke kartlagt som flomutsatt i dag.", pct)
+		p.Level = scoreLevel(0)
+		return p
+	}
+
+	pos := float64(matched) - pct/20
+	if pos < 0 {
+		pos = 0
+	}
+	lo := int(pos)
+	frac := pos - float64(lo)
+	score := float64(floodLevels[lo].score)
+	if frac > 0 && lo+1 < len(floodLevels) {
+		score += frac * float64(floodLevels[lo+1].score-floodLevels[lo].score)
+	}
+
+	p.Score = int(score + 0.5)
+	p.Level = scoreLevel(p.Score)
+	p.Description = loc.Sprintf("%s med %.0f %% klimapåslag", loc.T(floodLevels[matched].label), pct)
+	p.Details = loc.Sprintf("Med NVEs klimapåslag på %.0f %% i flomvannføring kan dagens %s opptre omtrent like ofte som en %s.", pct, loc.T(floodLevels[matched].label), loc.T(floodLevels[int(pos+0.5)].label))
+	return p
+}
+
+// projectFloodAwareness scales the flood awareness score by the klimapåslag.
+func projectFloodAwareness(sc *climateScenario, knr string, score int, loc localizer) *HazardProjection {
+	pct := float64(floodClimateSurcharge(knr)) * sc.FloodShare
+	projected := int(float64(score)*(1+pct/100) + 0.5)
+	if projected > 100 {
+		projected = 100
+	}
+
+	p := &HazardProjection{
+		Scenario: sc.Name,
+		Score:    projected,
+		Level:    scoreLevel(projected),
+	}
+	if score > 0 {
+		p.Description = loc.Sprintf("Flomaktsomhetsområde med %.0f %% klimapåslag", pct)
+		p.Details = loc.T("Økt flomvannføring gjør flom i aktsomhetsområdet mer sannsynlig.")
+	} else {
+		p.Description = loc.T("Ikke i aktsomhetsområde")
+	}
+	return p
+}
+
+// projectStormSurge re-evaluates storm surge with elevation reduced by the
+// projected sea level rise.
+func projectStormSurge(sc *climateScenario, exposure stormSurgeExposure, elevation *float64, uncertainty float64, loc localizer) *HazardProjection {
+	p := &HazardProjection{Scenario: sc.Name}
+	if !exposure.HasData || elevation == nil {
+		p.Level = scoreLevel(0)
+		p.Description = loc.T("Ingen stormflodata")
+		return p
+	}
+
+	effective := *elevation - sc.SeaLevelRise
+	score, _, _ := stormSurgeScore(effective, uncertainty, loc)
+	p.Score = exposure.scale(score)
+	p.Level = scoreLevel(p.Score)
+	p.Description = loc.Sprintf("Effektivt %.1f moh. med %.2f m havnivåstigning", effective, sc.SeaLevelRise)
+	p.Details = loc.Sprintf("Landsgjennomsnittet i Kartverkets framskrivninger er omtrent %.2f m havnivåstigning innen %s. Adressen ligger da effektivt %.1f m over dagens middelvann.", sc.SeaLevelRise, sc.Name, effective)
+	return p
+}
+
+// projectOverall recomputes the overall score using projected hazard scores
+// where available and present scores otherwise.
+func projectOverall(sc *climateScenario, hazards []HazardResult, elevation *float64, uncertainty float64, knr string, loc localizer) *ScenarioProjection {
+	projected := make([]HazardResult, len(hazards))
+	for i, h := range hazards {
+		projected[i] = h
+		if h.Projected != nil {
+			projected[i].Score = h.Projected.Score
+		}
+	}
+
+	var elev *float64
+	if elevation != nil {
+		e := *elevation - sc.SeaLevelRise
+		elev = &e
+	}
+
+	score, level, _ := calculateRisk(projected, elev, uncertainty, knr, loc)
+	return &ScenarioProjection{
+		Scenario:          sc.Name,
+		OverallScore:      score,
+		OverallLevel:      level,
+		SeaLevelRiseM:     sc.SeaLevelRise,
+		FloodSurchargePct: float64(floodClimateSurcharge(knr)) * sc.FloodShare,
+		Note:              loc.T("Grovt anslag: havnivåstigningen er et landsgjennomsnitt og klimapåslaget er forenklet per fylke, ikke beregnet for kommunen."),
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestProjectFloodZones(t *testing.T) {
+	loc := newLocalizer(langBokmal)
+	tests := []struct {
+		name     string
+		scenario string
+		knr      string
+		matched  int
+		score    int
+		level    string
+	}{
+		{"not in a zone", "2100", "4601", -1, 0, "low"},
+		// Vestland has 40 % klimapåslag: two steps by 2100, one by 2050.
+		{"200-year zone, 40 %, 2100", "2100", "4601", 4, 55, "high"},
+		{"200-year zone, 40 %, 2050", "2050", "4601", 4, 40, "medium"},
+		// Oslo has 20 %: half a step by 2050 is interpolated.
+		{"200-year zone, 20 %, 2050", "2050", "0301", 4, 33, "medium"},
+		{"100-year zone, 20 %, 2100", "2100", "0301", 3, 55, "high"},
+		{"10-year zone stays at the top", "2100", "4601", 0, 90, "very_high"},
+		{"short kommunenummer", "2100", "4", 4, 40, "medium"},
+	}
+	for _, tt := range tests {
+		sc, ok := parseScenario(tt.scenario)
+		if !ok || sc == nil {
+			t.Fatalf("scenario %q not found", tt.scenario)
+		}
+		p := projectFloodZones(sc, tt.knr, tt.matched, loc)
+		if p.Score != tt.score || p.Level != tt.level {
+			t.Errorf("%s: score %d (%s), want %d (%s)", tt.name, p.Score, p.Level, tt.score, tt.level)
+		}
+		if p.Scenario != tt.scenario {
+			t.Errorf("%s: scenario %q", tt.name, p.Scenario)
+		}
+	}
+}
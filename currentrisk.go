@@ -0,0 +1,95 @@
+package main
+
+import "time"
+
+// alertHazards maps MetAlerts event types to the static hazards they make
+// more likely while active. Flood, landslide and avalanche warnings are
+// issued by NVE and distributed through MetAlerts.
+var alertHazards = map[string][]string{
+	"flood":      {"flood_zones", "flood_awareness", "flood_screening", "historical_floods"},
+	"rainFlood":  {"flood_zones", "flood_awareness", "flood_screening", "historical_floods"},
+	"landslide":  {"landslide", "quick_clay", "combined_hazard", "historical_landslides", "historical_quick_clay"},
+	"avalanches": {"avalanche", "combined_hazard"},
+	"stormSurge": {"storm_surge"},
+}
+
+// severityFactor is the score multiplier applied to a matching static hazard
+// for each MetAlerts severity (yellow, orange, red).
+var severityFactor = map[string]float64{
+	"Moderate": 1.25,
+	"Severe":   1.5,
+	"Extreme":  2.0,
+}
+
+// calculateCurrentRisk escalates static hazard scores by the MetAlerts and
+// Varsom warnings that are active for the location. Hazards scoring zero are
+// not escalated: a warning only raises risk where the address is already
+// exposed. The base hazard results are left unchanged.
+func calculateCurrentRisk(hazards []HazardResult, alerts []WeatherAlert, varsom []VarsomWarning, elevation *float64, uncertainty float64, kommunenummer string, loc localizer) *CurrentRisk {
+	// Strongest factor and its alert per hazard ID.
+	type match struct {
+		factor float64
+		source string
+		alert  WeatherAlert
+	}
+	matches := make(map[string]match)
+	consider := func(source string, a WeatherAlert) {
+		factor, ok := severityFactor[a.Severity]
+		if !ok {
+			return
+		}
+		for _, id := range alertHazards[a.Event] {
+			if m, ok := matches[id]; !ok || factor > m.factor {
+				matches[id] = match{factor: factor, source: source, alert: a}
+			}
+		}
+	}
+	now := time.Now()
+	for _, a := range alerts {
+		// Upcoming alerts are listed but do not raise the risk yet.
+		if a.activeAt(now) {
+			consider("MET", a)
+		}
+	}
+	for _, w := range varsom {
+		if a, ok := varsomAlertEquivalent(w, now); ok {
+			consider("Varsom", a)
+		}
+	}
+
+	current := make([]HazardResult, len(hazards))
+	var escalations []HazardEscalation
+	for i, h := range hazards {
+		current[i] = h
+		m, ok := matches[h.ID]
+		if !ok || h.Score == 0 || h.Error != "" {
+			continue
+		}
+		score := int(float64(h.Score)*m.factor + 0.5)
+		if score > 100 {
+			score = 100
+		}
+		current[i].Score = score
+		escalations = append(escalations, HazardEscalation{
+			HazardID:     h.ID,
+			Source:       m.source,
+			Event:        m.alert.Event,
+			Severity:     m.alert.Severity,
+			BaseScore:    h.Score,
+			CurrentScore: score,
+		})
+	}
+
+	score, level, _ := calculateRisk(current, elevation, uncertainty, kommunenummer, loc)
+	cr := &CurrentRisk{
+		Score:       score,
+		Level:       level,
+		Escalations: escalations,
+	}
+	if len(escalations) == 0 {
+		cr.Summary = loc.T("Ingen aktive farevarsler påvirker de registrerte farene på adressen.")
+	} else {
+		cr.Summary = loc.Sprintf("Aktive farevarsler øker risikoen nå (score %d/100). Følg med på varsler fra MET og NVE.", score)
+	}
+	return cr
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -15,26 +17,116 @@ type elevationResponse struct {
 }
 
 type elevationPoint struct {
-	Z     *float64 `json:"z"`
-	Datakilde string `json:"datakilde"`
+	Z         *float64 `json:"z"`
+	Datakilde string   `json:"datakilde"`
 }
 
-// getElevation returns the elevation in meters at the given coordinates.
-func getElevation(ctx context.Context, cache *Cache, lat, lon float64) (*float64, error) {
+// elevationDatum is the vertical datum of all høydedata elevations.
+const elevationDatum = "NN2000"
+
+// elevationSources gives the grid resolution and typical vertical
+// uncertainty (one standard deviation, in meters) of each høydedata source.
+// Laser-scanned DTM1 is accurate to decimeters; the coarser models are
+// interpolated and can be off by meters in steep terrain.
+var elevationSources = map[string]ElevationSource{
+	"dtm1":  {ResolutionM: 1, UncertaintyM: 0.2},
+	"dtm10": {ResolutionM: 10, UncertaintyM: 2},
+	"dtm50": {ResolutionM: 50, UncertaintyM: 4},
+}
+
+// elevationDefaultUncertaintyM is assumed for unknown sources.
+const elevationDefaultUncertaintyM = 2.0
+
+// describeElevationSource returns metadata for a høydedata datakilde.
+func describeElevationSource(datakilde string) *ElevationSource {
+	src, ok := elevationSources[strings.ToLower(datakilde)]
+	if !ok {
+		src.UncertaintyM = elevationDefaultUncertaintyM
+	}
+	src.Source = datakilde
+	src.Datum = elevationDatum
+	return &src
+}
+
+// getElevation returns the elevation in meters at the given coordinates and
+// the source it was taken from.
+func getElevation(ctx context.Context, cache *Cache, lat, lon float64) (*float64, *ElevationSource, error) {
 	u := fmt.Sprintf("%s?nord=%f&ost=%f&koordsys=4326&geession=false", elevationURL, lat, lon)
 
 	data, err := cachedGet(ctx, cache, u, elevationCacheTTL)
 	if err != nil {
-		return nil, fmt.Errorf("elevation: %w", err)
+		return nil, nil, fmt.Errorf("elevation: %w", err)
 	}
 
 	var result elevationResponse
 	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("elevation decode: %w", err)
+		return nil, nil, fmt.Errorf("elevation decode: %w", err)
 	}
 
 	if len(result.Points) > 0 && result.Points[0].Z != nil {
-		return result.Points[0].Z, nil
+		return result.Points[0].Z, describeElevationSource(result.Points[0].Datakilde), nil
 	}
-	return nil, nil
+	return nil, nil, nil
+}
+
+// elevationClass is an elevation compared with a threshold.
+type elevationClass int
+
+const (
+	elevationBelow elevationClass = iota
+	elevationBorderline
+	elevationAbove
+)
+
+// compareElevation classifies an elevation against a threshold. Values
+// within the data's uncertainty of the threshold are borderline: the true
+// ground height may be on either side.
+func compareElevation(z, threshold, uncertainty float64) elevationClass {
+	switch {
+	case z+uncertainty < threshold:
+		return elevationBelow
+	case z-uncertainty < threshold:
+		return elevationBorderline
+	default:
+		return elevationAbove
+	}
+}
+
+// elevationBatchSize is the høydedata API's limit on points per request.
+const elevationBatchSize = 50
+
+// getElevations returns the elevation for each [lat, lon] point, in order,
+// batching requests. Points without data are nil.
+func getElevations(ctx context.Context, cache *Cache, points [][2]float64) ([]*float64, error) {
+	elevations := make([]*float64, len(points))
+
+	for start := 0; start < len(points); start += elevationBatchSize {
+		end := min(start+elevationBatchSize, len(points))
+
+		coords := make([]string, 0, end-start)
+		for _, p := range points[start:end] {
+			coords = append(coords, fmt.Sprintf("[%f,%f]", p[1], p[0]))
+		}
+		u := fmt.Sprintf("%s?punkter=%s&koordsys=4326", elevationURL,
+			url.QueryEscape("["+strings.Join(coords, ",")+"]"))
+
+		data, err := cachedGet(ctx, cache, u, elevationCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("elevations: %w", err)
+		}
+
+		var result elevationResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("elevations decode: %w", err)
+		}
+
+		// The API returns points in request order.
+		for i, pt := range result.Points {
+			if start+i < end {
+				elevations[start+i] = pt.Z
+			}
+		}
+	}
+
+	return elevations, nil
 }
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestCompareElevation(t *testing.T) {
+	tests := []struct {
+		name                    string
+		z, threshold, uncertain float64
+		want                    elevationClass
+	}{
+		{"well below", 1, 3, 0.5, elevationBelow},
+		{"just below the band", 2.25, 3, 0.5, elevationBelow},
+		{"lower edge of the band", 2.5, 3, 0.5, elevationBorderline},
+		{"on the threshold", 3, 3, 0.5, elevationBorderline},
+		{"just inside the upper edge", 3.25, 3, 0.5, elevationBorderline},
+		{"upper edge of the band", 3.5, 3, 0.5, elevationAbove},
+		{"well above", 10, 3, 0.5, elevationAbove},
+		{"no uncertainty, on the threshold", 3, 3, 0, elevationAbove},
+		{"no uncertainty, below", 2.75, 3, 0, elevationBelow},
+		{"coarse model widens the band", 0, 3, 4, elevationBorderline},
+	}
+	for _, tt := range tests {
+		if got := compareElevation(tt.z, tt.threshold, tt.uncertain); got != tt.want {
+			t.Errorf("%s: compareElevation(%v, %v, %v) = %v, want %v",
+				tt.name, tt.z, tt.threshold, tt.uncertain, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeElevationSource(t *testing.T) {
+	tests := []struct {
+		datakilde   string
+		resolution  float64
+		uncertainty float64
+	}{
+		{"dtm1", 1, 0.2},
+		{"DTM10", 10, 2},
+		{"dtm50", 50, 4},
+		{"ukjent", 0, elevationDefaultUncertaintyM},
+		{"", 0, elevationDefaultUncertaintyM},
+	}
+	for _, tt := range tests {
+		src := describeElevationSource(tt.datakilde)
+		if src.Source != tt.datakilde || src.Datum != elevationDatum {
+			t.Errorf("%q: source %q datum %q", tt.datakilde, src.Source, src.Datum)
+		}
+		if src.ResolutionM != tt.resolution || src.UncertaintyM != tt.uncertainty {
+			t.Errorf("%q: resolution %v uncertainty %v, want %v %v",
+				tt.datakilde, src.ResolutionM, src.UncertaintyM, tt.resolution, tt.uncertainty)
+		}
+	}
+	// The table entries must not be modified through the returned pointer.
+	describeElevationSource("dtm1").UncertaintyM = 99
+	if elevationSources["dtm1"].UncertaintyM != 0.2 {
+		t.Error("describeElevationSource aliases elevationSources")
+	}
+}
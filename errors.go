@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Error codes returned to API clients, at the top level and per hazard.
+const (
+	codeInvalidRequest      = "invalid_request"
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeNotFound            = "not_found"
+	codeRateLimited         = "rate_limited"
+	codeInvalidData         = "invalid_data"
+	codeTimeout             = "timeout"
+	codeInternal            = "internal_error"
+)
+
+// upstreamError is a failed call to an external API, classified by Code.
+type upstreamError struct {
+	Code       string
+	URL        string
+	StatusCode int // 0 when no response was received
+	Err        error
+}
+
+func (e *upstreamError) Error() string {
+	switch {
+	case e.StatusCode != 0:
+		return fmt.Sprintf("fetching %s: status %d", e.URL, e.StatusCode)
+	case e.Err != nil:
+		return fmt.Sprintf("fetching %s: %v", e.URL, e.Err)
+	default:
+		return fmt.Sprintf("fetching %s: %s", e.URL, e.Code)
+	}
+}
+
+func (e *upstreamError) Unwrap() error { return e.Err }
+
+// statusCodeError classifies a non-200 upstream response.
+func statusCodeError(url string, status int) *upstreamError {
+	code := codeUpstreamUnavailable
+	switch status {
+	case http.StatusNotFound:
+		code = codeNotFound
+	case http.StatusTooManyRequests:
+		code = codeRateLimited
+	}
+	return &upstreamError{Code: code, URL: url, StatusCode: status}
+}
+
+// transportError classifies a request that got no response.
+func transportError(url string, err error) *upstreamError {
+	code := codeUpstreamUnavailable
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &ne) && ne.Timeout()) {
+		code = codeTimeout
+	}
+	return &upstreamError{Code: code, URL: url, Err: err}
+}
+
+// errorCode returns the client-facing code for an error from an upstream
+// call chain. Decode failures count as invalid data.
+func errorCode(err error) string {
+	var ue *upstreamError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &ue):
+		return ue.Code
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return codeInvalidData
+	case errors.Is(err, context.DeadlineExceeded):
+		return codeTimeout
+	default:
+		return codeUpstreamUnavailable
+	}
+}
+
+// isNotFound reports whether err is an upstream 404.
+func isNotFound(err error) bool {
+	return errorCode(err) == codeNotFound
+}
+
+// hazardErrorMessages are the user-facing texts for failed hazard checks,
+// in Bokmål.
+var hazardErrorMessages = map[string]string{
+	codeUpstreamUnavailable: "Kunne ikke hente data",
+	codeNotFound:            "Fant ikke data",
+	codeRateLimited:         "Datakilden er overbelastet, prøv igjen senere",
+	codeInvalidData:         "Ugyldig svar fra datakilden",
+	codeTimeout:             "Datakilden svarte ikke i tide",
+}
+
+// setError marks a hazard result as failed, with a message and code
+// derived from err.
+func (h *HazardResult) setError(err error, loc localizer) {
+	h.ErrorCode = errorCode(err)
+	h.Error = loc.T(hazardErrorMessages[h.ErrorCode])
+	h.Level = "unknown"
+}
+
+// apiError is the JSON body of a failed API request.
+type apiError struct {
+	Error string `json:"error"` // human-readable
+	Code  string `json:"code"`
+}
+
+// writeError writes an error response with a machine-readable code.
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, apiError{Error: msg, Code: code})
+}
+
+// writeUpstreamError writes an error response for a failed upstream call,
+// choosing the status from its classification.
+func writeUpstreamError(w http.ResponseWriter, err error, msg string) {
+	code := errorCode(err)
+	status := http.StatusBadGateway
+	switch code {
+	case codeNotFound:
+		status = http.StatusNotFound
+	case codeTimeout:
+		status = http.StatusGatewayTimeout
+	case codeRateLimited:
+		status = http.StatusServiceUnavailable
+	}
+	writeError(w, status, code, msg)
+}
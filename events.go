@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits for the /api/events explorer.
+const (
+	eventsMaxRadiusM    = 20_000
+	eventsDefaultRadius = 1_000
+	eventsMaxLimit      = 200
+	eventsDefaultLimit  = 50
+
+	// eventsExportMaxPages caps CSV export and summary fetches.
+	eventsExportMaxPages = 20
+)
+
+// eventSource is an NVE point layer of historical events.
+type eventSource struct {
+	ID          string // HistoricalEvent.Source and the /api/events source parameter
+	URL         string
+	DateField   string // event date, used for filtering and ordering
+	DefaultType string // type name for events without a skredType
+	// SkredFields is set for layers with skredType and damage fields.
+	SkredFields bool
+}
+
+var (
+	sourceSkred = eventSource{
+		ID:          "skredhendelser",
+		URL:         skredHendelserURL,
+		DateField:   "skredTidspunkt",
+		DefaultType: "Skred (ukjent type)",
+		SkredFields: true,
+	}
+	sourceFlood = eventSource{
+		ID:          "flomhendelser",
+		URL:         flomHendelserURL,
+		DateField:   "flomDato",
+		DefaultType: "Flom",
+	}
+	sourceQuickClay = eventSource{
+		ID:          "kvikkleireskred",
+		URL:         kvikkleireHendelserURL,
+		DateField:   "skredTidspunkt",
+		DefaultType: "Kvikkleireskred",
+		SkredFields: true,
+	}
+)
+
+var eventSources = map[string]eventSource{
+	sourceSkred.ID:     sourceSkred,
+	sourceFlood.ID:     sourceFlood,
+	sourceQuickClay.ID: sourceQuickClay,
+}
+
+// eventQuery describes a filtered, paginated query against an NVE event
+// layer around a point.
+type eventQuery struct {
+	Source         eventSource
+	Lat, Lon       float64
+	RadiusM        int
+	From, To       string // YYYY-MM-DD, inclusive; empty for open
+	Types          []int  // skredType codes; empty for all
+	BuildingDamage bool
+	RoadDamage     bool
+	Fatalities     bool
+	Offset         int
+	Limit          int
+}
+
+// where builds the ArcGIS SQL where clause for the query's filters. All
+// values are validated before they get here, so no quoting is needed beyond
+// the fixed date literal format.
+func (q eventQuery) where() string {
+	clauses := []string{"1=1"}
+	if q.From != "" {
+		clauses = append(clauses, fmt.Sprintf("%s >= DATE '%s'", q.Source.DateField, q.From))
+	}
+	if q.To != "" {
+		// Standardized queries have no date arithmetic; compare against the next day.
+		if t, err := time.Parse("2006-01-02", q.To); err == nil {
+			clauses = append(clauses, fmt.Sprintf("%s < DATE '%s'", q.Source.DateField, t.AddDate(0, 0, 1).Format("2006-01-02")))
+		}
+	}
+	if len(q.Types) > 0 {
+		codes := make([]string, len(q.Types))
+		for i, t := range q.Types {
+			codes[i] = strconv.Itoa(t)
+		}
+		clauses = append(clauses, fmt.Sprintf("skredType IN (%s)", strings.Join(codes, ",")))
+	}
+	if q.BuildingDamage {
+		clauses = append(clauses, "bygnSkadet = 'Ja'")
+	}
+	if q.RoadDamage {
+		clauses = append(clauses, "vegSkadet = 'Ja'")
+	}
+	if q.Fatalities {
+		clauses = append(clauses, "totAntPersOmkommet > 0")
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// queryEvents runs an event query with a server-side distance buffer, newest
+// events first, and returns one page of results.
+func queryEvents(ctx context.Context, cache *Cache, q eventQuery) (EventsResponse, error) {
+	params := url.Values{
+		"geometry":          {fmt.Sprintf("%f,%f", q.Lon, q.Lat)},
+		"geometryType":      {"esriGeometryPoint"},
+		"inSR":              {"4326"},
+		"outSR":             {"4326"},
+		"spatialRel":        {"esriSpatialRelIntersects"},
+		"distance":          {strconv.Itoa(q.RadiusM)},
+		"units":             {"esriSRUnit_Meter"},
+		"where":             {q.where()},
+		"outFields":         {"*"},
+		"returnGeometry":    {"true"},
+		"orderByFields":     {q.Source.DateField + " DESC"},
+		"resultOffset":      {strconv.Itoa(q.Offset)},
+		"resultRecordCount": {strconv.Itoa(q.Limit)},
+		"f":                 {"json"},
+	}
+	u := q.Source.URL + "?" + params.Encode()
+
+	data, err := cachedGet(ctx, cache, u, nveCacheTTL)
+	if err != nil {
+		return EventsResponse{}, fmt.Errorf("%s events: %w", q.Source.ID, err)
+	}
+
+	var result eventLayerResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return EventsResponse{}, fmt.Errorf("%s events decode: %w", q.Source.ID, err)
+	}
+
+	resp := EventsResponse{
+		Events:  make([]HistoricalEvent, 0, len(result.Features)),
+		Offset:  q.Offset,
+		Limit:   q.Limit,
+		HasMore: result.ExceededTransferLimit,
+	}
+	seen := make(map[string]bool)
+	for _, f := range result.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		e := eventFromFeature(f, q.Source, q.Lat, q.Lon)
+		key := eventKey(e)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		resp.Events = append(resp.Events, e)
+	}
+	return resp, nil
+}
+
+// fetchAllEvents pages through every result of an event query, starting at
+// q.Offset with q.Limit per page, deduplicating across pages. The bool result
+// reports whether paging stopped at maxPages before the upstream ran out.
+func fetchAllEvents(ctx context.Context, cache *Cache, q eventQuery, maxPages int) ([]HistoricalEvent, bool, error) {
+	seen := make(map[string]bool)
+	var events []HistoricalEvent
+	start := q.Offset
+
+	for page := 0; page < maxPages; page++ {
+		q.Offset = start + page*q.Limit
+
+		resp, err := queryEvents(ctx, cache, q)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, e := range resp.Events {
+			key := eventKey(e)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			events = append(events, e)
+		}
+
+		if !resp.HasMore {
+			return events, false, nil
+		}
+	}
+	return events, true, nil
+}
+
+// parseEventQuery validates the /api/events query parameters. It returns a
+// user-facing error message when a parameter is invalid.
+func parseEventQuery(v url.Values) (eventQuery, string) {
+	q := eventQuery{
+		Source:  sourceSkred,
+		RadiusM: eventsDefaultRadius,
+		Limit:   eventsDefaultLimit,
+	}
+
+	var msg string
+	q.Lat, q.Lon, msg = parseLatLon(v)
+	if msg != "" {
+		return q, msg
+	}
+
+	if s := v.Get("source"); s != "" {
+		src, ok := eventSources[s]
+		if !ok {
+			return q, "invalid source"
+		}
+		q.Source = src
+	}
+
+	if s := v.Get("radius"); s != "" {
+		r, err := strconv.Atoi(s)
+		if err != nil || r < 1 || r > eventsMaxRadiusM {
+			return q, "invalid radius"
+		}
+		q.RadiusM = r
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *string
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if s := v.Get(p.name); s != "" {
+			if _, err := time.Parse("2006-01-02", s); err != nil {
+				return q, "invalid " + p.name + " date"
+			}
+			*p.dst = s
+		}
+	}
+
+	if s := v.Get("type"); s != "" {
+		for _, part := range strings.Split(s, ",") {
+			code, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return q, "invalid type"
+			}
+			if _, ok := skredTypeNames[code]; !ok {
+				return q, "invalid type"
+			}
+			q.Types = append(q.Types, code)
+		}
+	}
+
+	q.BuildingDamage = v.Get("building_damage") == "true"
+	q.RoadDamage = v.Get("road_damage") == "true"
+	q.Fatalities = v.Get("fatalities") == "true"
+
+	if !q.Source.SkredFields && (len(q.Types) > 0 || q.BuildingDamage || q.RoadDamage || q.Fatalities) {
+		return q, "type and damage filters are not supported for this source"
+	}
+
+	if s := v.Get("offset"); s != "" {
+		o, err := strconv.Atoi(s)
+		if err != nil || o < 0 {
+			return q, "invalid offset"
+		}
+		q.Offset = o
+	}
+	if s := v.Get("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > eventsMaxLimit {
+			return q, "invalid limit"
+		}
+		q.Limit = l
+	}
+
+	return q, ""
+}
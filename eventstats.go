@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// seasonOfMonth maps a calendar month to its meteorological season.
+var seasonOfMonth = map[time.Month]string{
+	time.December: "winter", time.January: "winter", time.February: "winter",
+	time.March: "spring", time.April: "spring", time.May: "spring",
+	time.June: "summer", time.July: "summer", time.August: "summer",
+	time.September: "autumn", time.October: "autumn", time.November: "autumn",
+}
+
+// summaryHorizonYears is the horizon for the probability estimate, matching
+// a typical mortgage.
+const summaryHorizonYears = 30
+
+// Return intervals need a few events over a decent span; a single recent
+// event would otherwise give an interval near zero and a certain event.
+const (
+	returnIntervalMinEvents = 3
+	returnIntervalMinYears  = 10
+)
+
+// summarizeEvents aggregates events into counts per type, decade, month and
+// season, damage totals and return interval estimates. Return intervals
+// assume the record is complete from the first dated event until now, which
+// NSDB is not; they are lower bounds on how often events occur.
+func summarizeEvents(events []HistoricalEvent, now time.Time) HistoricalSummary {
+	s := HistoricalSummary{
+		TotalEvents: len(events),
+		ByDecade:    make(map[string]int),
+		BySeason:    make(map[string]int),
+		ByMonth:     make([]int, 12),
+	}
+
+	byType := make(map[string]*TypeStats)
+	var first time.Time
+
+	for _, e := range events {
+		ts, ok := byType[e.Type]
+		if !ok {
+			ts = &TypeStats{Type: e.Type, TypeCode: e.TypeCode}
+			byType[e.Type] = ts
+		}
+		ts.Count++
+
+		if e.BuildingDamage {
+			s.BuildingDamage++
+			ts.BuildingDamage++
+		}
+		if e.RoadDamage {
+			s.RoadDamage++
+		}
+		s.Fatalities += e.Fatalities
+		ts.Fatalities += e.Fatalities
+
+		t, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			continue
+		}
+		s.DatedEvents++
+		ts.dated++
+		s.ByDecade[fmt.Sprintf("%ds", t.Year()/10*10)]++
+		s.ByMonth[t.Month()-1]++
+		s.BySeason[seasonOfMonth[t.Month()]]++
+
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if e.Date > s.LastEvent {
+			s.LastEvent = e.Date
+		}
+		if e.Date > ts.LastEvent {
+			ts.LastEvent = e.Date
+		}
+	}
+
+	if !first.IsZero() {
+		s.FirstEvent = first.Format("2006-01-02")
+		years := now.Sub(first).Hours() / (365.25 * 24)
+		s.ObservedYears = math.Round(years*10) / 10
+		s.ReturnIntervalYears, s.ProbabilityInHorizon = returnInterval(years, s.DatedEvents)
+		for _, ts := range byType {
+			ts.ReturnIntervalYears, _ = returnInterval(years, ts.dated)
+		}
+	}
+	s.HorizonYears = summaryHorizonYears
+
+	s.ByType = make([]TypeStats, 0, len(byType))
+	for _, ts := range byType {
+		s.ByType = append(s.ByType, *ts)
+	}
+	sort.Slice(s.ByType, func(i, j int) bool {
+		if s.ByType[i].Count != s.ByType[j].Count {
+			return s.ByType[i].Count > s.ByType[j].Count
+		}
+		return s.ByType[i].Type < s.ByType[j].Type
+	})
+
+	return s
+}
+
+// returnInterval estimates the mean return interval in years from count
+// events over an observation period, and the Poisson probability of at least
+// one event within summaryHorizonYears. It gives nothing for fewer than
+// returnIntervalMinEvents events or a period under returnIntervalMinYears.
+func returnInterval(years float64, count int) (*float64, *float64) {
+	if count < returnIntervalMinEvents || years < returnIntervalMinYears {
+		return nil, nil
+	}
+	interval := math.Round(years/float64(count)*10) / 10
+	p := 1 - math.Exp(-summaryHorizonYears*float64(count)/years)
+	p = math.Round(p*100) / 100
+	return &interval, &p
+}
+
+// writeSummaryCSV writes the summary as long-format rows of
+// dimension, key and value, for spreadsheet use.
+func writeSummaryCSV(w io.Writer, s HistoricalSummary) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"dimension", "key", "value"}}
+
+	itoa := strconv.Itoa
+	ftoa := func(f *float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f, 'f', -1, 64)
+	}
+
+	rows = append(rows,
+		[]string{"total", "events", itoa(s.TotalEvents)},
+		[]string{"total", "dated_events", itoa(s.DatedEvents)},
+		[]string{"total", "building_damage", itoa(s.BuildingDamage)},
+		[]string{"total", "road_damage", itoa(s.RoadDamage)},
+		[]string{"total", "fatalities", itoa(s.Fatalities)},
+		[]string{"total", "first_event", s.FirstEvent},
+		[]string{"total", "last_event", s.LastEvent},
+		[]string{"total", "return_interval_years", ftoa(s.ReturnIntervalYears)},
+		[]string{"total", fmt.Sprintf("probability_%dy", s.HorizonYears), ftoa(s.ProbabilityInHorizon)},
+		[]string{"total", "truncated", strconv.FormatBool(s.Truncated)},
+	)
+	for _, ts := range s.ByType {
+		rows = append(rows,
+			[]string{"type", ts.Type, itoa(ts.Count)},
+			[]string{"type_return_interval_years", ts.Type, ftoa(ts.ReturnIntervalYears)},
+		)
+	}
+
+	decades := make([]string, 0, len(s.ByDecade))
+	for d := range s.ByDecade {
+		decades = append(decades, d)
+	}
+	sort.Strings(decades)
+	for _, d := range decades {
+		rows = append(rows, []string{"decade", d, itoa(s.ByDecade[d])})
+	}
+
+	for i, n := range s.ByMonth {
+		rows = append(rows, []string{"month", itoa(i + 1), itoa(n)})
+	}
+	for _, season := range []string{"winter", "spring", "summer", "autumn"} {
+		rows = append(rows, []string{"season", season, itoa(s.BySeason[season])})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing summary csv: %w", err)
+	}
+	return nil
+}
+
+// writeEventsCSV writes one row per event.
+func writeEventsCSV(w io.Writer, events []HistoricalEvent) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{
+		"id", "type_code", "type", "date", "location", "latitude", "longitude",
+		"distance_m", "building_damage", "road_damage", "fatalities", "description",
+	}}
+	for _, e := range events {
+		rows = append(rows, []string{
+			e.ID,
+			strconv.Itoa(e.TypeCode),
+			e.Type,
+			e.Date,
+			e.Location,
+			strconv.FormatFloat(e.Latitude, 'f', 6, 64),
+			strconv.FormatFloat(e.Longitude, 'f', 6, 64),
+			strconv.Itoa(e.DistanceMeters),
+			strconv.FormatBool(e.BuildingDamage),
+			strconv.FormatBool(e.RoadDamage),
+			strconv.Itoa(e.Fatalities),
+			e.Description,
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing events csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeEvents(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		events []HistoricalEvent
+
+		dated     int
+		first     string
+		decades   map[string]int
+		interval  *float64
+		prob      *float64
+		typeCount map[string]int
+	}{
+		{
+			name:    "no events",
+			decades: map[string]int{},
+		},
+		{
+			name:      "one recent event",
+			events:    []HistoricalEvent{{Type: "Jordskred", Date: "2025-05-01"}},
+			dated:     1,
+			first:     "2025-05-01",
+			decades:   map[string]int{"2020s": 1},
+			typeCount: map[string]int{"Jordskred": 1},
+		},
+		{
+			name: "too short a span",
+			events: []HistoricalEvent{
+				{Type: "Jordskred", Date: "2020-05-01"},
+				{Type: "Jordskred", Date: "2021-05-01"},
+				{Type: "Jordskred", Date: "2022-05-01"},
+			},
+			dated:     3,
+			first:     "2020-05-01",
+			decades:   map[string]int{"2020s": 3},
+			typeCount: map[string]int{"Jordskred": 3},
+		},
+		{
+			name: "many events",
+			events: []HistoricalEvent{
+				{Type: "Snøskred", Date: "1925-06-01"},
+				{Type: "Snøskred", Date: "1950-01-10"},
+				{Type: "Jordskred", Date: "1975-10-01"},
+				{Type: "Snøskred", Date: "2000-02-01"},
+			},
+			dated:     4,
+			first:     "1925-06-01",
+			decades:   map[string]int{"1920s": 1, "1950s": 1, "1970s": 1, "2000s": 1},
+			interval:  ptr(25),
+			prob:      ptr(0.7),
+			typeCount: map[string]int{"Snøskred": 3, "Jordskred": 1},
+		},
+		{
+			name: "undated events",
+			events: []HistoricalEvent{
+				{Type: "Flom"},
+				{Type: "Flom", Date: "ukjent"},
+				{Type: "Flom", Date: "1890-04-01"},
+			},
+			dated:     1,
+			first:     "1890-04-01",
+			decades:   map[string]int{"1890s": 1},
+			typeCount: map[string]int{"Flom": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := summarizeEvents(tt.events, now)
+			if s.TotalEvents != len(tt.events) || s.DatedEvents != tt.dated || s.FirstEvent != tt.first {
+				t.Errorf("got %d events, %d dated, first %q; want %d, %d, %q", s.TotalEvents, s.DatedEvents, s.FirstEvent, len(tt.events), tt.dated, tt.first)
+			}
+			if len(s.ByDecade) != len(tt.decades) {
+				t.Errorf("by decade %v, want %v", s.ByDecade, tt.decades)
+			}
+			for d, n := range tt.decades {
+				if s.ByDecade[d] != n {
+					t.Errorf("by decade %v, want %v", s.ByDecade, tt.decades)
+				}
+			}
+			if !equalPtr(s.ReturnIntervalYears, tt.interval) || !equalPtr(s.ProbabilityInHorizon, tt.prob) {
+				t.Errorf("interval %v, probability %v; want %v, %v", deref(s.ReturnIntervalYears), deref(s.ProbabilityInHorizon), deref(tt.interval), deref(tt.prob))
+			}
+			for _, ts := range s.ByType {
+				if ts.Count != tt.typeCount[ts.Type] {
+					t.Errorf("type %s: count %d, want %d", ts.Type, ts.Count, tt.typeCount[ts.Type])
+				}
+			}
+		})
+	}
+}
+
+func TestReturnInterval(t *testing.T) {
+	tests := []struct {
+		years    float64
+		count    int
+		interval *float64
+		prob     *float64
+	}{
+		{100, 0, nil, nil},
+		{100, 1, nil, nil},
+		{100, 2, nil, nil},
+		{0.1, 5, nil, nil},
+		{9.9, 10, nil, nil},
+		{10, 3, ptr(3.3), ptr(1)},
+		{100, 4, ptr(25), ptr(0.7)},
+		{300, 3, ptr(100), ptr(0.26)},
+	}
+	for _, tt := range tests {
+		interval, prob := returnInterval(tt.years, tt.count)
+		if !equalPtr(interval, tt.interval) || !equalPtr(prob, tt.prob) {
+			t.Errorf("returnInterval(%v, %d) = %v, %v; want %v, %v", tt.years, tt.count, deref(interval), deref(prob), deref(tt.interval), deref(tt.prob))
+		}
+	}
+}
+
+func equalPtr(a, b *float64) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func deref(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
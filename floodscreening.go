@@ -0,0 +1,88 @@
+package main
+
+// floodScreeningLevel is one step of the flood screening estimate: an
+// address within MaxDistanceM of water and at most MaxHeightM above its
+// surface gets Score.
+type floodScreeningLevel struct {
+	MaxDistanceM int
+	MaxHeightM   float64
+	Score        int
+}
+
+// floodScreeningLevels are rough rules of thumb: low ground close to water
+// floods first. The highest scoring match wins. MaxHeightM is widened by the
+// elevation uncertainty, since the true height may be lower than measured.
+var floodScreeningLevels = []floodScreeningLevel{
+	{MaxDistanceM: 100, MaxHeightM: 2, Score: 50},
+	{MaxDistanceM: 200, MaxHeightM: 5, Score: 30},
+	{MaxDistanceM: 500, MaxHeightM: 10, Score: 15},
+}
+
+// waterKindNames are Bokmål names for Watercourse.Kind.
+var waterKindNames = map[string]string{
+	"river": "elv",
+	"lake":  "innsjø",
+}
+
+// checkFloodScreening estimates flood exposure where NVE has not mapped flood
+// zones, from the distance to the nearest river or lake and the address's
+// height above the water surface. The result is flagged as estimated.
+// uncertainty is the vertical uncertainty of the elevation data in meters.
+func checkFloodScreening(wc *Watercourse, wcErr error, elevation *float64, uncertainty float64, loc localizer) HazardResult {
+	h := HazardResult{
+		ID:          "flood_screening",
+		Name:        loc.T("Flomscreening (estimat)"),
+		Description: loc.T("Estimert flomfare ut fra avstand og høyde over nærmeste elv eller innsjø"),
+		Coverage:    coverageEstimated,
+		Estimated:   true,
+	}
+
+	if wcErr != nil {
+		h.setError(wcErr, loc)
+		return h
+	}
+	if elevation == nil {
+		h.Level = "unknown"
+		h.Details = loc.T("Høyden på adressen er ukjent, så flomfaren kan ikke anslås.")
+		return h
+	}
+
+	if wc == nil {
+		h.Level = scoreLevel(0)
+		h.Details = loc.Sprintf("Ingen elv eller innsjø innen %d m. Estimatet erstatter ikke flomsonekart.", watercourseSearchM)
+		return h
+	}
+
+	name := loc.T(waterKindNames[wc.Kind])
+	if wc.Name != "" {
+		name += " (" + wc.Name + ")"
+	}
+
+	if wc.HeightAboveM == nil {
+		h.Level = "unknown"
+		h.Details = loc.Sprintf("Nærmeste %s er %d m unna, men høyden over vannflaten er ukjent.", name, wc.DistanceM)
+		return h
+	}
+
+	height := *wc.HeightAboveM
+	// borderline is the matching level, if it matches only within the
+	// uncertainty.
+	var borderline *floodScreeningLevel
+	for _, l := range floodScreeningLevels {
+		if wc.DistanceM > l.MaxDistanceM || l.Score <= h.Score {
+			continue
+		}
+		switch {
+		case height <= l.MaxHeightM:
+			h.Score, borderline = l.Score, nil
+		case compareElevation(height, l.MaxHeightM, uncertainty) == elevationBorderline:
+			h.Score, borderline = l.Score, &l
+		}
+	}
+	h.Level = scoreLevel(h.Score)
+	h.Details = loc.Sprintf("Nærmeste %s er %d m unna, og adressen ligger %.1f m over vannflaten. Dette er et grovt estimat fra terrengdata, ikke et flomsonekart.", name, wc.DistanceM, height)
+	if borderline != nil {
+		h.Details += loc.Sprintf(" Høyden er usikker (±%.1f m), så adressen kan ligge mindre enn %.0f m over vannflaten.", uncertainty, borderline.MaxHeightM)
+	}
+	return h
+}
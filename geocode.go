@@ -41,12 +41,12 @@ func searchAddresses(ctx context.Context, query string) ([]Address, error) {
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("geocode request: %w", err)
+		return nil, fmt.Errorf("geocode: %w", transportError(u, err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("geocode: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("geocode: %w", statusCodeError(u, resp.StatusCode))
 	}
 
 	var result geonorgeResponse
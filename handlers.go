@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var knrPattern = regexp.MustCompile(`^\d{4}$`)
@@ -14,81 +20,341 @@ var knrPattern = regexp.MustCompile(`^\d{4}$`)
 func handleSearch(w http.ResponseWriter, r *http.Request) {
 	q := strings.TrimSpace(r.URL.Query().Get("q"))
 	if q == "" || len(q) < 2 {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query too short"})
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "query too short")
 		return
 	}
 	if len(q) > 200 {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query too long"})
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "query too long")
 		return
 	}
 
 	addresses, err := searchAddresses(r.Context(), q)
 	if err != nil {
 		log.Printf("search error: %v", err)
-		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "search failed"})
+		writeUpstreamError(w, err, "search failed")
 		return
 	}
 
 	writeJSON(w, http.StatusOK, addresses)
 }
 
-func handleRisk(cache *Cache) http.HandlerFunc {
+func handleRisk(cache *Cache, store *Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		q := r.URL.Query()
-
-		lat, err := strconv.ParseFloat(q.Get("lat"), 64)
-		if err != nil || lat < 57 || lat > 72 {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid latitude"})
+		req, msg := parseRiskRequest(r)
+		if msg != "" {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, msg)
 			return
 		}
 
-		lon, err := strconv.ParseFloat(q.Get("lon"), 64)
-		if err != nil || lon < 4 || lon > 32 {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid longitude"})
-			return
-		}
+		ctx, fetches := withFetchLog(r.Context())
+		resp := assessRisk(ctx, cache, req)
+		resp.Sources = fetches.sources()
+		saveAssessment(store, req, &resp, fetches)
 
-		knr := strings.TrimSpace(q.Get("knr"))
-		if !knrPattern.MatchString(knr) {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid kommunenummer"})
+		setLanguageHeaders(w, req.Loc)
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func handleReport(cache *Cache, store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, msg := parseRiskRequest(r)
+		if msg != "" {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, msg)
 			return
 		}
 
-		text := q.Get("text")
-		if len(text) > 500 {
-			text = text[:500]
+		ctx, fetches := withFetchLog(r.Context())
+		resp := assessRisk(ctx, cache, req)
+		var mapImg image.Image
+		if img, err := hazardMap(ctx, cache, req.Address.Latitude, req.Address.Longitude); err != nil {
+			log.Printf("report map error: %v", err)
+		} else {
+			mapImg = img
 		}
-		kommune := q.Get("kommune")
-		if len(kommune) > 200 {
-			kommune = kommune[:200]
+		resp.Sources = fetches.sources()
+		saveAssessment(store, req, &resp, fetches)
+		pdf := renderReport(resp, mapImg, req.Loc)
+
+		setLanguageHeaders(w, req.Loc)
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `inline; filename="risikorapport.pdf"`)
+		if _, err := w.Write(pdf); err != nil {
+			log.Printf("report write error: %v", err)
 		}
+	}
+}
 
-		addr := Address{
+// riskRequest is a parsed risk assessment request.
+type riskRequest struct {
+	Address   Address
+	Scenario  *climateScenario
+	Buildings bool
+	Save      bool // store a snapshot, see saveAssessment
+	Loc       localizer
+}
+
+// parseRiskRequest reads the query parameters shared by the risk and report
+// endpoints. It returns a user-facing error message when one is invalid.
+func parseRiskRequest(r *http.Request) (riskRequest, string) {
+	q := r.URL.Query()
+
+	lat, lon, msg := parseLatLon(q)
+	if msg != "" {
+		return riskRequest{}, msg
+	}
+
+	knr := strings.TrimSpace(q.Get("knr"))
+	if !knrPattern.MatchString(knr) {
+		return riskRequest{}, "invalid kommunenummer"
+	}
+
+	sc, ok := parseScenario(q.Get("scenario"))
+	if !ok {
+		return riskRequest{}, "invalid scenario"
+	}
+
+	loc, ok := requestLocalizer(r)
+	if !ok {
+		return riskRequest{}, "invalid lang"
+	}
+
+	text := q.Get("text")
+	if len(text) > 500 {
+		text = text[:500]
+	}
+	kommune := q.Get("kommune")
+	if len(kommune) > 200 {
+		kommune = kommune[:200]
+	}
+
+	return riskRequest{
+		Address: Address{
 			Text:          text,
 			Latitude:      lat,
 			Longitude:     lon,
 			Kommunenummer: knr,
 			Kommunenavn:   kommune,
+		},
+		Scenario:  sc,
+		Buildings: q.Get("buildings") == "true",
+		Save:      q.Get("save") == "true",
+		Loc:       loc,
+	}, ""
+}
+
+// assessRisk runs a full risk assessment. The upstream responses it uses
+// go to the context's fetch log, from which the caller fills in Sources.
+func assessRisk(ctx context.Context, cache *Cache, req riskRequest) RiskResponse {
+	addr, sc, loc := req.Address, req.Scenario, req.Loc
+	knr := addr.Kommunenummer
+
+	a := assessHazards(ctx, cache, addr, sc, loc)
+	uncertainty := a.elevationUncertainty()
+	overallScore, overallLevel, summary := calculateRisk(a.Hazards, a.Elevation, uncertainty, knr, loc)
+	cachePreview(cache, req, a.Hazards, overallScore, overallLevel, summary)
+
+	resp := RiskResponse{
+		Address:          addr,
+		OverallScore:     overallScore,
+		OverallLevel:     overallLevel,
+		Summary:          summary,
+		Elevation:        a.Elevation,
+		ElevationSource:  a.ElevationSource,
+		Hazards:          a.Hazards,
+		WeatherAlerts:    a.Alerts,
+		VarsomWarnings:   a.VarsomWarnings,
+		HistoricalEvents: a.HistoricalEvents,
+		Terrain:          a.Terrain,
+		Watercourse:      a.Watercourse,
+
+		HistoricalEventsTruncated: a.HistoricalEventsTruncated,
+		CurrentRisk:               calculateCurrentRisk(a.Hazards, a.Alerts, a.VarsomWarnings, a.Elevation, uncertainty, knr, loc),
+	}
+	if len(a.HistoricalEvents) > 0 {
+		summary := summarizeEvents(a.HistoricalEvents, time.Now())
+		summary.Truncated = a.HistoricalEventsTruncated
+		resp.HistoricalSummary = &summary
+	}
+	if sc != nil {
+		resp.Projection = projectOverall(sc, a.Hazards, a.Elevation, uncertainty, knr, loc)
+	}
+	if req.Buildings {
+		buildings, err := assessBuildings(ctx, cache, addr, uncertainty, loc)
+		if err != nil {
+			log.Printf("buildings error: %v", err)
+			resp.BuildingsError = loc.T("Kunne ikke hente bygningsdata")
 		}
+		resp.Buildings = buildings
+		if note := buildingsNote(buildings, overallScore, loc); note != "" {
+			resp.Summary += " " + note
+		}
+	}
+
+	resp.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+	return resp
+}
 
-		hazards, elevation, alerts, historicalEvents := assessHazards(r.Context(), cache, addr)
-		overallScore, overallLevel, summary := calculateRisk(hazards, elevation, knr)
+// saveAssessment stores a snapshot of the assessment and sets its ID, when
+// the request asks for it and storage is enabled. A failure is logged and
+// the response goes out without an ID.
+func saveAssessment(store *Store, req riskRequest, resp *RiskResponse, fetches *fetchLog) {
+	if store == nil || !req.Save {
+		return
+	}
+	q := permalinkQuery(req)
+	q.Set("lang", req.Loc.Lang)
+	snap := AssessmentSnapshot{
+		CreatedAt: resp.GeneratedAt,
+		Request:   q.Encode(),
+		Response:  *resp,
+		Upstream:  fetches.responses(),
+	}
+	if err := store.Save(&snap); err != nil {
+		log.Printf("store error: %v", err)
+		return
+	}
+	resp.AssessmentID = snap.ID
+}
 
-		resp := RiskResponse{
-			Address:          addr,
-			OverallScore:     overallScore,
-			OverallLevel:     overallLevel,
-			Summary:          summary,
-			Elevation:        elevation,
-			Hazards:          hazards,
-			WeatherAlerts:    alerts,
-			HistoricalEvents: historicalEvents,
+func handleAssessment(store *Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "assessment storage is disabled")
+			return
+		}
+		data, err := store.Load(r.PathValue("id"))
+		if errors.Is(err, errAssessmentNotFound) {
+			writeError(w, http.StatusNotFound, codeNotFound, "assessment not found")
+			return
+		}
+		if err != nil {
+			log.Printf("store error: %v", err)
+			writeError(w, http.StatusInternalServerError, codeInternal, "internal server error")
+			return
+		}
+		// Served byte for byte as stored.
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+func handleEvents(cache *Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, msg := parseEventQuery(r.URL.Query())
+		if msg != "" {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, msg)
+			return
+		}
+
+		// CSV export covers every matching event rather than one page.
+		if r.URL.Query().Get("format") == "csv" {
+			events, truncated, err := fetchAllEvents(r.Context(), cache, q, eventsExportMaxPages)
+			if err != nil {
+				log.Printf("events export error: %v", err)
+				writeUpstreamError(w, err, "events lookup failed")
+				return
+			}
+			setCSVHeaders(w, "skredhendelser.csv", truncated)
+			if err := writeEventsCSV(w, events); err != nil {
+				log.Printf("events csv error: %v", err)
+			}
+			return
+		}
+
+		resp, err := queryEvents(r.Context(), cache, q)
+		if err != nil {
+			log.Printf("events error: %v", err)
+			writeUpstreamError(w, err, "events lookup failed")
+			return
 		}
 
 		writeJSON(w, http.StatusOK, resp)
 	}
 }
 
+func handleEventSummary(cache *Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, msg := parseEventQuery(r.URL.Query())
+		if msg != "" {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, msg)
+			return
+		}
+
+		events, truncated, err := fetchAllEvents(r.Context(), cache, q, eventsExportMaxPages)
+		if err != nil {
+			log.Printf("events summary error: %v", err)
+			writeUpstreamError(w, err, "events lookup failed")
+			return
+		}
+		if truncated {
+			log.Printf("events summary truncated at %d events", len(events))
+		}
+
+		summary := summarizeEvents(events, time.Now())
+		summary.Truncated = truncated
+		if r.URL.Query().Get("format") == "csv" {
+			setCSVHeaders(w, "skredstatistikk.csv", truncated)
+			if err := writeSummaryCSV(w, summary); err != nil {
+				log.Printf("events summary csv error: %v", err)
+			}
+			return
+		}
+
+		writeJSON(w, http.StatusOK, summary)
+	}
+}
+
+func handleKommune(cache *Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		knr := r.PathValue("knr")
+		if !knrPattern.MatchString(knr) {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid kommunenummer")
+			return
+		}
+		loc, ok := requestLocalizer(r)
+		if !ok {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid lang")
+			return
+		}
+
+		k, err := aggregateKommune(r.Context(), cache, knr, loc)
+		if err != nil {
+			log.Printf("kommune error: %v", err)
+			writeUpstreamError(w, err, "kommune aggregation failed")
+			return
+		}
+		setLanguageHeaders(w, loc)
+		writeJSON(w, http.StatusOK, k)
+	}
+}
+
+// setCSVHeaders marks a CSV download. X-Truncated tells that the export hit
+// its page limit and leaves out matching events.
+func setCSVHeaders(w http.ResponseWriter, filename string, truncated bool) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
+}
+
+// parseLatLon reads and bounds-checks lat/lon against mainland Norway.
+// It returns a user-facing error message when either is invalid.
+func parseLatLon(q url.Values) (float64, float64, string) {
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil || lat < 57 || lat > 72 {
+		return 0, 0, "invalid latitude"
+	}
+
+	lon, err := strconv.ParseFloat(q.Get("lon"), 64)
+	if err != nil || lon < 4 || lon > 32 {
+		return 0, 0, "invalid longitude"
+	}
+
+	return lat, lon, ""
+}
+
 func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
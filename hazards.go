@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"strings"
 	"sync"
@@ -21,31 +20,61 @@ var (
 	svcQuickClayOverview = nveServices[8]
 	svcAvalanche         = nveServices[9]
 	svcRockFall          = nveServices[10]
-	svcCombinedHazard    = nveServices[11]
+	svcHazardZone100yr   = nveServices[11]
+	svcHazardZone1000yr  = nveServices[12]
+	svcHazardZone5000yr  = nveServices[13]
+	svcFloodMapping      = nveServices[14]
+	svcHazardZoneMapping = nveServices[15]
 )
 
+// assessment is the combined outcome of all upstream checks for an address.
+type assessment struct {
+	Hazards          []HazardResult
+	Elevation        *float64
+	ElevationSource  *ElevationSource
+	Alerts           []WeatherAlert
+	VarsomWarnings   []VarsomWarning
+	HistoricalEvents []HistoricalEvent
+	Terrain          *Terrain
+	Watercourse      *Watercourse
+	// HistoricalEventsTruncated is set when not all events could be fetched.
+	HistoricalEventsTruncated bool
+}
+
+// elevationUncertainty returns the vertical uncertainty of the address
+// elevation in meters, or zero when it is unknown.
+func (a assessment) elevationUncertainty() float64 {
+	if a.ElevationSource == nil {
+		return 0
+	}
+	return a.ElevationSource.UncertaintyM
+}
+
 // assessHazards runs all hazard checks in parallel and returns results.
-// Elevation is fetched first (needed by storm surge), then the rest fan out.
-func assessHazards(ctx context.Context, cache *Cache, addr Address) ([]HazardResult, *float64, []WeatherAlert, []HistoricalEvent) {
+// Elevation is fetched first (needed by storm surge and terrain), then the
+// rest fan out.
+// When sc is non-nil, flood and storm surge results carry a projection for
+// that climate scenario. Texts are generated in the language of loc.
+func assessHazards(ctx context.Context, cache *Cache, addr Address, sc *climateScenario, loc localizer) assessment {
 	lat, lon := addr.Latitude, addr.Longitude
 
 	// Fetch elevation first — storm surge depends on it.
-	elev, err := getElevation(ctx, cache, lat, lon)
+	elev, elevSrc, err := getElevation(ctx, cache, lat, lon)
 	if err != nil {
 		log.Printf("elevation error: %v", err)
 	}
 
 	var (
-		mu               sync.Mutex
-		hazards          []HazardResult
-		alerts           []WeatherAlert
-		historicalEvents []HistoricalEvent
-		wg               sync.WaitGroup
+		mu sync.Mutex
+		a  = assessment{Elevation: elev, ElevationSource: elevSrc}
+		wg sync.WaitGroup
+
+		uncertainty = a.elevationUncertainty()
 	)
 
 	addHazard := func(h HazardResult) {
 		mu.Lock()
-		hazards = append(hazards, h)
+		a.Hazards = append(a.Hazards, h)
 		mu.Unlock()
 	}
 
@@ -53,172 +82,368 @@ func assessHazards(ctx context.Context, cache *Cache, addr Address) ([]HazardRes
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkFloodZones(ctx, cache, lat, lon))
-	}()
-
-	// Flood awareness
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		addHazard(checkSingleNVE(ctx, cache, lat, lon, svcFloodAwareness, "flood_awareness", "Flomaktsomhet", "Flomaktsomhetsområde", 35))
+		h, matched := checkFloodZones(ctx, cache, lat, lon, loc)
+		if sc != nil && h.Error == "" {
+			h.Projected = projectFloodZones(sc, addr.Kommunenummer, matched, loc)
+		}
+		addHazard(h)
 	}()
 
-	// Landslide
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		addHazard(checkSingleNVE(ctx, cache, lat, lon, svcLandslide, "landslide", "Jord- og flomskred", "Aktsomhetsområde for jord- og flomskred", 60))
-	}()
+	// Awareness maps (flood, landslide, avalanche, rock fall)
+	for _, c := range awarenessChecks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := c.check(ctx, cache, lat, lon, loc)
+			if c.id == "flood_awareness" && sc != nil && h.Error == "" {
+				h.Projected = projectFloodAwareness(sc, addr.Kommunenummer, h.Score, loc)
+			}
+			addHazard(h)
+		}()
+	}
 
 	// Quick clay
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkQuickClay(ctx, cache, lat, lon))
+		addHazard(checkQuickClay(ctx, cache, lat, lon, loc))
 	}()
 
-	// Avalanche
+	// Landslide hazard zones (100, 1000, 5000 year)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkSingleNVE(ctx, cache, lat, lon, svcAvalanche, "avalanche", "Snøskred", "Aktsomhetsområde for snøskred", 70))
+		addHazard(checkHazardZones(ctx, cache, lat, lon, loc))
 	}()
 
-	// Rock fall
+	// Storm surge (uses elevation — now safe, fetched above)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkSingleNVE(ctx, cache, lat, lon, svcRockFall, "rock_fall", "Steinsprang", "Aktsomhetsområde for steinsprang", 65))
+		h, exposure := checkStormSurge(ctx, cache, addr.Kommunenummer, elev, uncertainty, loc)
+		if sc != nil && h.Error == "" {
+			h.Projected = projectStormSurge(sc, exposure, elev, uncertainty, loc)
+		}
+		addHazard(h)
 	}()
 
-	// Combined hazard zones
+	// Historical landslides, floods and quick clay slides (scored below,
+	// once hazard zones are known)
+	type eventResult struct {
+		events      []HistoricalEvent
+		truncatedAt int
+		err         error
+	}
+	var landslides, floods, quickClay eventResult
+	for _, f := range []struct {
+		src eventSource
+		dst *eventResult
+	}{{sourceSkred, &landslides}, {sourceFlood, &floods}, {sourceQuickClay, &quickClay}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events, truncatedAt, err := getHistoricalEvents(ctx, cache, f.src, lat, lon)
+			*f.dst = eventResult{events, truncatedAt, err}
+		}()
+	}
+
+	// Slope and aspect (adjusts slope hazards below)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkSingleNVE(ctx, cache, lat, lon, svcCombinedHazard, "combined_hazard", "Skredfaresoner", "Faresone for skred", 75))
+		t, err := sampleTerrain(ctx, cache, lat, lon)
+		if err != nil {
+			log.Printf("terrain error: %v", err)
+			return
+		}
+		mu.Lock()
+		a.Terrain = t
+		mu.Unlock()
 	}()
 
-	// Storm surge (uses elevation — now safe, fetched above)
+	// Nearest river or lake (flood screening below)
+	var (
+		watercourse    *Watercourse
+		watercourseErr error
+	)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		addHazard(checkStormSurge(ctx, cache, addr.Kommunenummer, elev))
+		watercourse, watercourseErr = nearestWatercourse(ctx, cache, lat, lon, elev)
+		if watercourseErr != nil {
+			log.Printf("watercourse error: %v", watercourseErr)
+		}
 	}()
 
-	// Historical landslide events
+	// Weather alerts
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		h, events := getSkredHendelser(ctx, cache, lat, lon)
+		alerts, err := getWeatherAlerts(ctx, cache, lat, lon, loc)
+		if err != nil {
+			log.Printf("metalerts error: %v", err)
+			return
+		}
 		mu.Lock()
-		hazards = append(hazards, h)
-		historicalEvents = events
+		a.Alerts = alerts
 		mu.Unlock()
 	}()
 
-	// Weather alerts
+	// NVE Varsom flood, landslide and avalanche warnings
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		a, err := getWeatherAlerts(ctx, cache, lat, lon)
-		if err != nil {
-			log.Printf("metalerts error: %v", err)
-			return
-		}
+		warnings := getVarsomWarnings(ctx, cache, addr.Kommunenummer, lat, lon, loc)
 		mu.Lock()
-		alerts = a
+		a.VarsomWarnings = warnings
 		mu.Unlock()
 	}()
 
 	wg.Wait()
-	return hazards, elev, alerts, historicalEvents
+
+	// Quick clay slides in SkredHendelser are scored with the quick clay registry.
+	if landslides.err == nil && quickClay.err == nil {
+		landslides.events, quickClay.events = splitQuickClay(landslides.events, quickClay.events)
+	}
+
+	applyTerrain(a.Hazards, a.Terrain, loc)
+	a.Watercourse = watercourse
+
+	// Flood screening stands in for flood zones where NVE has not mapped them.
+	for _, h := range a.Hazards {
+		if h.ID == "flood_zones" && h.Coverage == coverageNotMapped {
+			a.Hazards = append(a.Hazards, checkFloodScreening(watercourse, watercourseErr, elev, uncertainty, loc))
+			break
+		}
+	}
+
+	static := a.Hazards
+	for _, h := range []struct {
+		kind historicalKind
+		res  eventResult
+	}{{kindLandslides, landslides}, {kindFloods, floods}, {kindQuickClay, quickClay}} {
+		a.Hazards = append(a.Hazards, historicalHazard(h.kind, h.res.events, h.res.truncatedAt, h.res.err, static, elev, loc))
+		a.HistoricalEvents = append(a.HistoricalEvents, h.res.events...)
+		a.HistoricalEventsTruncated = a.HistoricalEventsTruncated || h.res.truncatedAt > 0
+	}
+	sortByDistance(a.HistoricalEvents)
+
+	return a
+}
+
+// floodLevel is one flood return period layer, ordered from most to least
+// frequent in floodLevels.
+type floodLevel struct {
+	svc   nveService
+	label string
+	score int
+}
+
+var floodLevels = []floodLevel{
+	{svcFlood10yr, "10-årsflom", 90},
+	{svcFlood20yr, "20-årsflom", 75},
+	{svcFlood50yr, "50-årsflom", 55},
+	{svcFlood100yr, "100-årsflom", 40},
+	{svcFlood200yr, "200-årsflom", 25},
 }
 
 // checkFloodZones queries all flood return period layers and returns
-// a single hazard result for the worst match.
-func checkFloodZones(ctx context.Context, cache *Cache, lat, lon float64) HazardResult {
-	type floodLevel struct {
-		svc   nveService
-		label string
-		score int
+// a single hazard result for the worst match, along with the index of
+// that match in floodLevels (-1 if none). All layers live in the same
+// Flomsoner1 MapServer, so they are resolved with one identify request
+// together with the mapping coverage layer.
+func checkFloodZones(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) (HazardResult, int) {
+	layers := []int{svcFloodMapping.Layer}
+	for _, fl := range floodLevels {
+		layers = append(layers, fl.svc.Layer)
 	}
 
-	levels := []floodLevel{
-		{svcFlood10yr, "10-årsflom", 90},
-		{svcFlood20yr, "20-årsflom", 75},
-		{svcFlood50yr, "50-årsflom", 55},
-		{svcFlood100yr, "100-årsflom", 40},
-		{svcFlood200yr, "200-årsflom", 25},
+	byLayer, err := identifyNVE(ctx, cache, svcFlood10yr.BaseURL, layers, lat, lon)
+	if err != nil {
+		log.Printf("flood identify: %v", err)
+		h := HazardResult{ID: "flood_zones", Name: loc.T("Flomsoner")}
+		h.setError(err, loc)
+		return h, -1
 	}
 
-	bestScore := 0
-	bestLabel := ""
-
-	for _, fl := range levels {
-		resp, err := queryNVE(ctx, cache, fl.svc, lat, lon)
-		if err != nil {
-			log.Printf("flood query %s: %v", fl.label, err)
-			continue
-		}
-		if len(resp.Features) > 0 && fl.score > bestScore {
-			bestScore = fl.score
-			bestLabel = fl.label
+	// floodLevels is ordered most frequent first, so the first match is the worst.
+	bestIdx := -1
+	var bestFeatures []arcgisFeature
+	for i, fl := range floodLevels {
+		if features := byLayer[fl.svc.Layer]; len(features) > 0 {
+			bestIdx = i
+			bestFeatures = features
+			break
 		}
 	}
 
 	h := HazardResult{
-		ID:    "flood_zones",
-		Name:  "Flomsoner",
-		Score: bestScore,
-		Level: scoreLevel(bestScore),
+		ID:   "flood_zones",
+		Name: loc.T("Flomsoner"),
 	}
 
-	if bestScore > 0 {
-		h.Description = fmt.Sprintf("Innenfor %s-sone", bestLabel)
-		h.Details = fmt.Sprintf("Adressen ligger i en kartlagt flomsone (%s). Risiko for oversvømmelse ved ekstremvær.", bestLabel)
-	} else {
-		h.Description = "Ikke i kartlagt flomsone"
-		h.Details = "Ingen registrerte flomsoner på dette punktet."
+	switch {
+	case bestIdx >= 0:
+		best := floodLevels[bestIdx]
+		za := parseZoneAttributes(bestFeatures)
+		// The return period is given by the layer itself.
+		za.ReturnPeriod = 0
+		h.Coverage = coverageMapped
+		h.Score = za.refineScore(best.score)
+		h.Description = loc.Sprintf("Innenfor %s-sone", loc.T(best.label))
+		h.Details = loc.Sprintf("Adressen ligger i en kartlagt flomsone (%s). Risiko for oversvømmelse ved ekstremvær.", loc.T(best.label))
+		za.apply(&h, loc)
+	case len(byLayer[svcFloodMapping.Layer]) > 0:
+		h.Coverage = coverageMapped
+		h.Description = loc.T("Ikke i kartlagt flomsone")
+		h.Details = loc.T("Området er flomsonekartlagt av NVE, og adressen ligger utenfor flomsonene.")
+	default:
+		h.Coverage = coverageNotMapped
+		h.Description = loc.T("Ikke flomsonekartlagt")
+		h.Details = loc.T("NVE har ikke flomsonekartlagt dette området. Score 0 betyr at faren er ukjent, ikke at den er fraværende.")
+	}
+	h.Level = scoreLevel(h.Score)
+
+	return h, bestIdx
+}
+
+// hazardZoneLevel is one landslide hazard zone layer. TEK17 §7-3 sets the
+// highest acceptable annual probability per safety class: S1 1/100,
+// S2 1/1000 and S3 1/5000. An address inside a zone fails every class
+// listed in fails.
+type hazardZoneLevel struct {
+	svc    nveService
+	period int
+	score  int
+	fails  []string
+}
+
+var hazardZoneLevels = []hazardZoneLevel{
+	{svcHazardZone100yr, 100, 75, []string{"S1", "S2", "S3"}},
+	{svcHazardZone1000yr, 1000, 55, []string{"S2", "S3"}},
+	{svcHazardZone5000yr, 5000, 35, []string{"S3"}},
+}
+
+// checkHazardZones queries all landslide hazard zone layers and returns a
+// single hazard result for the most probable zone, reporting which TEK17
+// safety classes the address does not satisfy. Like checkFloodZones, all
+// layers are resolved with one identify request.
+func checkHazardZones(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) HazardResult {
+	h := HazardResult{
+		ID:   "combined_hazard",
+		Name: loc.T("Skredfaresoner"),
+	}
+
+	layers := []int{svcHazardZoneMapping.Layer}
+	for _, zl := range hazardZoneLevels {
+		layers = append(layers, zl.svc.Layer)
+	}
+
+	byLayer, err := identifyNVE(ctx, cache, svcHazardZone100yr.BaseURL, layers, lat, lon)
+	if err != nil {
+		log.Printf("hazard zone identify: %v", err)
+		h.setError(err, loc)
+		return h
 	}
 
+	// hazardZoneLevels is ordered most probable first, so the first match is the worst.
+	bestIdx := -1
+	var bestFeatures []arcgisFeature
+	for i, zl := range hazardZoneLevels {
+		if features := byLayer[zl.svc.Layer]; len(features) > 0 {
+			bestIdx = i
+			bestFeatures = features
+			break
+		}
+	}
+
+	switch {
+	case bestIdx >= 0:
+		best := hazardZoneLevels[bestIdx]
+		za := parseZoneAttributes(bestFeatures)
+		// The return period is given by the layer itself.
+		za.ReturnPeriod = 0
+		h.Coverage = coverageMapped
+		h.Score = za.refineScore(best.score)
+		h.ReturnPeriod = best.period
+		h.FailedSafetyClasses = best.fails
+		h.Description = loc.Sprintf("Faresone for skred (1/%d)", best.period)
+		h.Details = loc.Sprintf("Adressen ligger i en kartlagt faresone for skred med årlig sannsynlighet 1/%d. Oppfyller ikke TEK17 sikkerhetsklasse %s.", best.period, strings.Join(best.fails, ", "))
+		za.apply(&h, loc)
+	case len(byLayer[svcHazardZoneMapping.Layer]) > 0:
+		h.Coverage = coverageMapped
+		h.Description = loc.T("Ikke i kartlagt faresone")
+		h.Details = loc.T("Området er faresonekartlagt for skred av NVE, og adressen ligger utenfor faresonene.")
+	default:
+		h.Coverage = coverageNotMapped
+		h.Description = loc.T("Ikke faresonekartlagt")
+		h.Details = loc.T("NVE har ikke faresonekartlagt skred i dette området. Se aktsomhetskartene for snøskred, steinsprang og jord- og flomskred.")
+	}
+	h.Level = scoreLevel(h.Score)
+
 	return h
 }
 
+// awarenessCheck is a national awareness map scored by checkSingleNVE.
+type awarenessCheck struct {
+	svc          nveService
+	id, name     string
+	desc         string
+	presentScore int
+}
+
+var awarenessChecks = []awarenessCheck{
+	{svcFloodAwareness, "flood_awareness", "Flomaktsomhet", "Flomaktsomhetsområde", 35},
+	{svcLandslide, "landslide", "Jord- og flomskred", "Aktsomhetsområde for jord- og flomskred", 60},
+	{svcAvalanche, "avalanche", "Snøskred", "Aktsomhetsområde for snøskred", 70},
+	{svcRockFall, "rock_fall", "Steinsprang", "Aktsomhetsområde for steinsprang", 65},
+}
+
+func (c awarenessCheck) check(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) HazardResult {
+	return checkSingleNVE(ctx, cache, lat, lon, c.svc, c.id, loc.T(c.name), loc.T(c.desc), c.presentScore, loc)
+}
+
 // checkSingleNVE queries a single NVE service and returns present/absent.
-func checkSingleNVE(ctx context.Context, cache *Cache, lat, lon float64, svc nveService, id, name, desc string, presentScore int) HazardResult {
+// All services checked this way are national awareness maps: coarse
+// screening that covers the whole country but is not detailed mapping.
+// name and desc are already translated.
+func checkSingleNVE(ctx context.Context, cache *Cache, lat, lon float64, svc nveService, id, name, desc string, presentScore int, loc localizer) HazardResult {
 	h := HazardResult{
-		ID:   id,
-		Name: name,
+		ID:       id,
+		Name:     name,
+		Coverage: coverageAwareness,
 	}
 
 	resp, err := queryNVE(ctx, cache, svc, lat, lon)
 	if err != nil {
-		h.Error = "Kunne ikke hente data"
-		h.Level = "unknown"
+		h.setError(err, loc)
+		h.Coverage = ""
 		log.Printf("nve %s error: %v", id, err)
 		return h
 	}
 
 	if len(resp.Features) > 0 {
-		h.Score = presentScore
-		h.Level = scoreLevel(presentScore)
+		za := parseZoneAttributes(resp.Features)
+		h.Score = za.refineScore(presentScore)
+		h.Level = scoreLevel(h.Score)
 		h.Description = desc
-		h.Details = fmt.Sprintf("Adressen ligger i %s.", strings.ToLower(desc))
+		h.Details = loc.Sprintf("Adressen ligger i %s.", strings.ToLower(desc))
+		za.apply(&h, loc)
 	} else {
 		h.Score = 0
 		h.Level = scoreLevel(0)
-		h.Description = "Ikke i aktsomhetsområde"
-		h.Details = fmt.Sprintf("Ingen registrert %s-fare på dette punktet.", strings.ToLower(name))
+		h.Description = loc.T("Ikke i aktsomhetsområde")
+		h.Details = loc.Sprintf("Ingen registrert %s-fare på dette punktet.", strings.ToLower(name))
 	}
 
 	return h
 }
 
 // checkQuickClay queries both detailed and overview quick clay services.
-func checkQuickClay(ctx context.Context, cache *Cache, lat, lon float64) HazardResult {
+func checkQuickClay(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) HazardResult {
 	h := HazardResult{
 		ID:   "quick_clay",
-		Name: "Kvikkleire",
+		Name: loc.T("Kvikkleire"),
 	}
 
 	// Try detailed first
@@ -234,29 +459,33 @@ func checkQuickClay(ctx context.Context, cache *Cache, lat, lon float64) HazardR
 			h.Score = 25
 		}
 		h.Level = scoreLevel(h.Score)
-		h.Description = fmt.Sprintf("Kvikkleiresone (faregrad: %s)", grade)
-		h.Details = "Adressen ligger i område med kartlagt kvikkleirefare."
+		h.Coverage = coverageMapped
+		h.Description = loc.Sprintf("Kvikkleiresone (faregrad: %s)", loc.T(grade))
+		h.Details = loc.T("Adressen ligger i område med kartlagt kvikkleirefare.")
+		parseZoneAttributes(resp.Features).apply(&h, loc)
 		return h
 	}
 
 	// Fallback to overview
 	resp2, err := queryNVE(ctx, cache, svcQuickClayOverview, lat, lon)
 	if err != nil {
-		h.Error = "Kunne ikke hente data"
-		h.Level = "unknown"
+		h.setError(err, loc)
 		return h
 	}
 
+	// Only the awareness map covers the point: detailed zones are mapped
+	// for selected areas only.
+	h.Coverage = coverageAwareness
 	if len(resp2.Features) > 0 {
 		h.Score = 40
 		h.Level = scoreLevel(h.Score)
-		h.Description = "Aktsomhetsområde for kvikkleire"
-		h.Details = "Adressen ligger i et generelt aktsomhetsområde for kvikkleire."
+		h.Description = loc.T("Aktsomhetsområde for kvikkleire")
+		h.Details = loc.T("Adressen ligger i et generelt aktsomhetsområde for kvikkleire.")
 	} else {
 		h.Score = 0
 		h.Level = scoreLevel(0)
-		h.Description = "Ikke i kvikkleireområde"
-		h.Details = "Ingen registrert kvikkleirefare på dette punktet."
+		h.Description = loc.T("Ikke i kvikkleireområde")
+		h.Details = loc.T("Ingen registrert kvikkleirefare på dette punktet.")
 	}
 
 	return h
@@ -274,37 +503,86 @@ func extractFaregrad(attrs map[string]any) string {
 }
 
 // checkStormSurge evaluates storm surge risk based on municipality consequence data.
-func checkStormSurge(ctx context.Context, cache *Cache, kommunenummer string, elevation *float64) HazardResult {
+// The elevation-based score is scaled by the share of the municipality's
+// buildings affected in its worst scenario, and the result lists every
+// scenario. uncertainty is the vertical uncertainty of elevation in meters.
+func checkStormSurge(ctx context.Context, cache *Cache, kommunenummer string, elevation *float64, uncertainty float64, loc localizer) (HazardResult, stormSurgeExposure) {
 	h := HazardResult{
 		ID:   "storm_surge",
-		Name: "Stormflo",
+		Name: loc.T("Stormflo"),
 	}
+	exposure := stormSurgeExposure{Factor: 1}
 
 	entries, err := getStormSurge(ctx, cache, kommunenummer)
-	if err != nil || len(entries) == 0 {
+	if err != nil {
+		h.setError(err, loc)
+		log.Printf("stormflo error: %v", err)
+		return h, exposure
+	}
+	if len(entries) == 0 {
 		h.Score = 0
 		h.Level = scoreLevel(0)
-		h.Description = "Ingen stormflodata"
-		h.Details = "Ingen stormflodata tilgjengelig for denne kommunen."
-		return h
+		h.Description = loc.T("Ingen stormflodata")
+		h.Details = loc.T("Ingen stormflodata tilgjengelig for denne kommunen.")
+		return h, exposure
+	}
+	exposure.HasData = true
+	h.StormSurgeScenarios = stormSurgeScenarios(entries)
+
+	worst := worstStormSurgeScenario(h.StormSurgeScenarios)
+	var shareNote string
+	if addresses, err := countKommuneAddresses(ctx, cache, kommunenummer); err != nil {
+		log.Printf("stormflo address count error: %v", err)
+	} else if addresses > 0 {
+		share := float64(worst.Buildings) / float64(addresses)
+		exposure.Factor = stormSurgeShareFactor(share)
+		shareNote = loc.Sprintf(" I verste scenario (%s, %s) berøres %d bygninger i kommunen, tilsvarende %.1f %% av adressene.", worst.Code, worst.Year, worst.Buildings, share*100)
 	}
 
-	if elevation != nil && *elevation < 3 {
-		h.Score = 50
-		h.Level = scoreLevel(h.Score)
-		h.Description = fmt.Sprintf("Lav kystbeliggenhet (%.1f moh.)", *elevation)
-		h.Details = fmt.Sprintf("Adressen ligger på bare %.1f moh. i en kystkommune med stormflorisiko. Kan bli berørt ved ekstreme stormflosituasjoner.", *elevation)
-	} else if elevation != nil && *elevation < 10 {
-		h.Score = 25
-		h.Level = scoreLevel(h.Score)
-		h.Description = fmt.Sprintf("Kystnær beliggenhet (%.1f moh.)", *elevation)
-		h.Details = fmt.Sprintf("Adressen ligger på %.1f moh. i en kystkommune. Moderat risiko for stormflo.", *elevation)
-	} else {
-		h.Score = 0
+	if elevation == nil {
 		h.Level = scoreLevel(0)
-		h.Description = "Over stormflonivå"
-		h.Details = "Adressen ligger høyt nok til at stormflo neppe er en trussel."
+		h.Description = loc.T("Over stormflonivå")
+		h.Details = loc.T("Adressen ligger høyt nok til at stormflo neppe er en trussel.")
+		return h, exposure
 	}
 
-	return h
+	score, desc, details := stormSurgeScore(*elevation, uncertainty, loc)
+	h.Score = exposure.scale(score)
+	h.Level = scoreLevel(h.Score)
+	h.Description = desc
+	h.Details = details
+	if h.Score > 0 {
+		h.Details += shareNote
+	}
+	return h, exposure
+}
+
+// stormSurgeScore scores storm surge exposure for an elevation in a coastal
+// municipality, returning the score, description and details. Elevations
+// within uncertainty of a threshold score halfway between the two levels.
+func stormSurgeScore(elevation, uncertainty float64, loc localizer) (int, string, string) {
+	uncertainNote := loc.Sprintf(" Høyden er usikker (±%.1f m), så adressen kan ligge både over og under denne grensen.", uncertainty)
+
+	switch low, coastal := compareElevation(elevation, 3, uncertainty), compareElevation(elevation, 10, uncertainty); {
+	case low == elevationBelow:
+		return 50,
+			loc.Sprintf("Lav kystbeliggenhet (%.1f moh.)", elevation),
+			loc.Sprintf("Adressen ligger på bare %.1f moh. i en kystkommune med stormflorisiko. Kan bli berørt ved ekstreme stormflosituasjoner.", elevation)
+	case low == elevationBorderline:
+		return 37,
+			loc.Sprintf("Lav kystbeliggenhet, usikker høyde (%.1f moh.)", elevation),
+			loc.Sprintf("Adressen ligger på omtrent %.1f moh. i en kystkommune med stormflorisiko, nær grensen på 3 moh.", elevation) + uncertainNote
+	case coastal == elevationBelow:
+		return 25,
+			loc.Sprintf("Kystnær beliggenhet (%.1f moh.)", elevation),
+			loc.Sprintf("Adressen ligger på %.1f moh. i en kystkommune. Moderat risiko for stormflo.", elevation)
+	case coastal == elevationBorderline:
+		return 12,
+			loc.Sprintf("Kystnær beliggenhet, usikker høyde (%.1f moh.)", elevation),
+			loc.Sprintf("Adressen ligger på omtrent %.1f moh. i en kystkommune, nær grensen på 10 moh.", elevation) + uncertainNote
+	default:
+		return 0,
+			loc.T("Over stormflonivå"),
+			loc.T("Adressen ligger høyt nok til at stormflo neppe er en trussel.")
+	}
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Response languages. Generated texts are written in Bokmål in the code and
+// looked up in the catalog of the requested language; texts missing from a
+// catalog fall back to Bokmål.
+const (
+	langBokmal  = "nb"
+	langNynorsk = "nn"
+	langEnglish = "en"
+)
+
+var catalogs = map[string]map[string]string{
+	langNynorsk: catalogNN,
+	langEnglish: catalogEN,
+}
+
+// langTags maps the primary subtag of a language tag to a supported
+// language. Plain "no" is taken as Bokmål.
+var langTags = map[string]string{
+	"nb": langBokmal, "nob": langBokmal, "no": langBokmal, "nor": langBokmal,
+	"nn": langNynorsk, "nno": langNynorsk,
+	"en": langEnglish, "eng": langEnglish,
+}
+
+// localizer translates generated texts into one language.
+type localizer struct {
+	Lang    string
+	catalog map[string]string
+}
+
+func newLocalizer(lang string) localizer {
+	return localizer{Lang: lang, catalog: catalogs[lang]}
+}
+
+// T returns the translation of a Bokmål text.
+func (l localizer) T(msg string) string {
+	if s, ok := l.catalog[msg]; ok {
+		return s
+	}
+	return msg
+}
+
+// Sprintf formats the translation of a Bokmål format string.
+func (l localizer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(l.T(format), args...)
+}
+
+// Join joins a list of items with the translation of "og".
+func (l localizer) Join(items []string) string {
+	return strings.Join(items, " "+l.T("og")+" ")
+}
+
+// parseLangTag resolves a language tag such as "nn-NO" or "en_GB".
+func parseLangTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	lang, ok := langTags[tag]
+	return lang, ok
+}
+
+// requestLocalizer picks the response language from the lang query
+// parameter, then the Accept-Language header, defaulting to Bokmål. It
+// reports false for an unsupported lang parameter.
+func requestLocalizer(r *http.Request) (localizer, bool) {
+	if tag := r.URL.Query().Get("lang"); tag != "" {
+		lang, ok := parseLangTag(tag)
+		return newLocalizer(lang), ok
+	}
+	return newLocalizer(negotiateLang(r.Header.Get("Accept-Language"))), true
+}
+
+// negotiateLang returns the supported language with the highest quality in
+// an Accept-Language header, preferring earlier entries on ties.
+func negotiateLang(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		lang, ok := parseLangTag(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return langBokmal
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// setLanguageHeaders marks a response as localized.
+func setLanguageHeaders(w http.ResponseWriter, l localizer) {
+	w.Header().Set("Content-Language", l.Lang)
+	w.Header().Add("Vary", "Accept-Language")
+}
@@ -0,0 +1,244 @@
+package main
+
+// catalogEN translates generated texts to English.
+var catalogEN = map[string]string{
+	"og": "and",
+
+	// Hazard names and awareness areas
+	"Flomsoner":            "Flood zones",
+	"Flomaktsomhet":        "Flood awareness",
+	"Flomaktsomhetsområde": "Flood awareness area",
+	"Jord- og flomskred":   "Debris flow and landslide",
+	"Aktsomhetsområde for jord- og flomskred": "Debris flow and landslide awareness area",
+	"Snøskred":                         "Snow avalanche",
+	"Aktsomhetsområde for snøskred":    "Snow avalanche awareness area",
+	"Steinsprang":                      "Rock fall",
+	"Aktsomhetsområde for steinsprang": "Rock fall awareness area",
+	"Kvikkleire":                       "Quick clay",
+	"Skredfaresoner":                   "Landslide hazard zones",
+	"Stormflo":                         "Storm surge",
+	"Flomscreening (estimat)":          "Flood screening (estimate)",
+	"Historiske skredhendelser":        "Historical landslides",
+	"Historiske flomhendelser":         "Historical floods",
+	"Historiske kvikkleireskred":       "Historical quick clay slides",
+	"Flomsoner (200-år)":               "Flood zones (200-year)",
+	"Skredfaresoner (1000-år)":         "Landslide hazard zones (1000-year)",
+
+	// Flood zones
+	"10-årsflom":               "10-year flood",
+	"20-årsflom":               "20-year flood",
+	"50-årsflom":               "50-year flood",
+	"100-årsflom":              "100-year flood",
+	"200-årsflom":              "200-year flood",
+	"Innenfor %s-sone":         "Within %s zone",
+	"Ikke i kartlagt flomsone": "Not in a mapped flood zone",
+	"Ikke flomsonekartlagt":    "No flood zone mapping",
+	"Adressen ligger i en kartlagt flomsone (%s). Risiko for oversvømmelse ved ekstremvær.":                     "The address is in a mapped flood zone (%s). Risk of flooding in extreme weather.",
+	"Området er flomsonekartlagt av NVE, og adressen ligger utenfor flomsonene.":                                "NVE has mapped flood zones in this area, and the address is outside them.",
+	"NVE har ikke flomsonekartlagt dette området. Score 0 betyr at faren er ukjent, ikke at den er fraværende.": "NVE has not mapped flood zones in this area. A score of 0 means the hazard is unknown, not that it is absent.",
+
+	// Landslide hazard zones
+	"Faresone for skred (1/%d)": "Landslide hazard zone (1/%d)",
+	"Ikke i kartlagt faresone":  "Not in a mapped hazard zone",
+	"Ikke faresonekartlagt":     "No hazard zone mapping",
+	"Adressen ligger i en kartlagt faresone for skred med årlig sannsynlighet 1/%d. Oppfyller ikke TEK17 sikkerhetsklasse %s.":   "The address is in a mapped landslide hazard zone with an annual probability of 1/%d. It does not meet TEK17 safety class %s.",
+	"Området er faresonekartlagt for skred av NVE, og adressen ligger utenfor faresonene.":                                       "NVE has mapped landslide hazard zones in this area, and the address is outside them.",
+	"NVE har ikke faresonekartlagt skred i dette området. Se aktsomhetskartene for snøskred, steinsprang og jord- og flomskred.": "NVE has not mapped landslide hazard zones in this area. See the awareness maps for snow avalanches, rock fall and debris flows.",
+
+	// Awareness maps
+	"Adressen ligger i %s.":                      "The address is in a %s.",
+	"Ikke i aktsomhetsområde":                    "Not in an awareness area",
+	"Ingen registrert %s-fare på dette punktet.": "No registered %s hazard at this point.",
+
+	// Zone attributes
+	"utløsningsområde":                     "release area",
+	"utløpsområde":                         "runout area",
+	" Beregnet vanndybde: %.1f m.":         " Estimated water depth: %.1f m.",
+	" Nominell årlig sannsynlighet: 1/%d.": " Nominal annual probability: 1/%d.",
+	" Kartlagt %s.":                        " Mapped in %s.",
+	" Kartleggingen er over 20 år gammel og kan være utdatert.": " The mapping is more than 20 years old and may be outdated.",
+
+	// Quick clay; hazard grades come from the NVE data
+	"Høy":                             "High",
+	"Hoy":                             "High",
+	"Lav":                             "Low",
+	"Middels":                         "Medium",
+	"Ukjent":                          "Unknown",
+	"Kvikkleiresone (faregrad: %s)":   "Quick clay zone (hazard class: %s)",
+	"Aktsomhetsområde for kvikkleire": "Quick clay awareness area",
+	"Ikke i kvikkleireområde":         "Not in a quick clay area",
+	"Adressen ligger i område med kartlagt kvikkleirefare.":          "The address is in an area with mapped quick clay hazard.",
+	"Adressen ligger i et generelt aktsomhetsområde for kvikkleire.": "The address is in a general quick clay awareness area.",
+	"Ingen registrert kvikkleirefare på dette punktet.":              "No registered quick clay hazard at this point.",
+
+	// Storm surge
+	"Ingen stormflodata": "No storm surge data",
+	"Over stormflonivå":  "Above storm surge level",
+	"Ingen stormflodata tilgjengelig for denne kommunen.":                                                                     "No storm surge data is available for this municipality.",
+	"Adressen ligger høyt nok til at stormflo neppe er en trussel.":                                                           "The address is high enough that storm surge is unlikely to be a threat.",
+	" I verste scenario (%s, %s) berøres %d bygninger i kommunen, tilsvarende %.1f %% av adressene.":                          " In the worst scenario (%s, %s), %d buildings in the municipality are affected, equivalent to %.1f %% of the addresses.",
+	" Høyden er usikker (±%.1f m), så adressen kan ligge både over og under denne grensen.":                                   " The elevation is uncertain (±%.1f m), so the address may be either above or below this limit.",
+	"Lav kystbeliggenhet (%.1f moh.)":                                                                                         "Low coastal location (%.1f m above sea level)",
+	"Lav kystbeliggenhet, usikker høyde (%.1f moh.)":                                                                          "Low coastal location, uncertain elevation (%.1f m above sea level)",
+	"Kystnær beliggenhet (%.1f moh.)":                                                                                         "Coastal location (%.1f m above sea level)",
+	"Kystnær beliggenhet, usikker høyde (%.1f moh.)":                                                                          "Coastal location, uncertain elevation (%.1f m above sea level)",
+	"Adressen ligger på bare %.1f moh. i en kystkommune med stormflorisiko. Kan bli berørt ved ekstreme stormflosituasjoner.": "The address is only %.1f m above sea level in a coastal municipality with storm surge risk. It may be affected by extreme storm surges.",
+	"Adressen ligger på omtrent %.1f moh. i en kystkommune med stormflorisiko, nær grensen på 3 moh.":                         "The address is about %.1f m above sea level in a coastal municipality with storm surge risk, close to the 3 m limit.",
+	"Adressen ligger på %.1f moh. i en kystkommune. Moderat risiko for stormflo.":                                             "The address is %.1f m above sea level in a coastal municipality. Moderate storm surge risk.",
+	"Adressen ligger på omtrent %.1f moh. i en kystkommune, nær grensen på 10 moh.":                                           "The address is about %.1f m above sea level in a coastal municipality, close to the 10 m limit.",
+
+	// Climate projections
+	"%s med %.0f %% klimapåslag":                     "%s with %.0f %% climate allowance",
+	"Flomaktsomhetsområde med %.0f %% klimapåslag":   "Flood awareness area with %.0f %% climate allowance",
+	"Effektivt %.1f moh. med %.2f m havnivåstigning": "Effectively %.1f m above sea level with %.2f m sea level rise",
+	"Flomsonekartene viser dagens situasjon. Med %.0f %% klimapåslag kan flomsonen utvides, men punktet er ikke kartlagt som flomutsatt i dag.":                 "The flood zone maps show the current situation. With a %.0f %% climate allowance the flood zone may grow, but the point is not mapped as flood-prone today.",
+	"Med NVEs klimapåslag på %.0f %% i flomvannføring kan dagens %s opptre omtrent like ofte som en %s.":                                                        "With NVE's climate allowance of %.0f %% in flood discharge, today's %s may occur about as often as a %s.",
+	"Økt flomvannføring gjør flom i aktsomhetsområdet mer sannsynlig.":                                                                                          "Increased flood discharge makes flooding in the awareness area more likely.",
+	"Landsgjennomsnittet i Kartverkets framskrivninger er omtrent %.2f m havnivåstigning innen %s. Adressen ligger da effektivt %.1f m over dagens middelvann.": "The national average in Kartverket's projections is about %.2f m sea level rise by %s. The address is then effectively %.1f m above today's mean sea level.",
+	"Grovt anslag: havnivåstigningen er et landsgjennomsnitt og klimapåslaget er forenklet per fylke, ikke beregnet for kommunen.":                              "A rough estimate: the sea level rise is a national average and the climate surcharge is simplified per county, not calculated for the municipality.",
+
+	// Flood screening
+	"elv":    "river",
+	"innsjø": "lake",
+	"Estimert flomfare ut fra avstand og høyde over nærmeste elv eller innsjø":                                                              "Estimated flood hazard from the distance to and height above the nearest river or lake",
+	"Høyden på adressen er ukjent, så flomfaren kan ikke anslås.":                                                                           "The elevation of the address is unknown, so the flood hazard cannot be estimated.",
+	"Ingen elv eller innsjø innen %d m. Estimatet erstatter ikke flomsonekart.":                                                             "No river or lake within %d m. The estimate does not replace flood zone maps.",
+	"Nærmeste %s er %d m unna, men høyden over vannflaten er ukjent.":                                                                       "The nearest %s is %d m away, but the height above the water surface is unknown.",
+	"Nærmeste %s er %d m unna, og adressen ligger %.1f m over vannflaten. Dette er et grovt estimat fra terrengdata, ikke et flomsonekart.": "The nearest %s is %d m away, and the address is %.1f m above the water surface. This is a rough estimate from terrain data, not a flood zone map.",
+	" Høyden er usikker (±%.1f m), så adressen kan ligge mindre enn %.0f m over vannflaten.":                                                " The elevation is uncertain (±%.1f m), so the address may be less than %.0f m above the water surface.",
+
+	// Terrain
+	" Terrenget innen 100 m er flatt (maks %.0f° helning), noe som reduserer faren.": " The terrain within 100 m is flat (at most %.0f° slope), which reduces the hazard.",
+	" Terrenget stiger %.0f m innen 100 m med opptil %.0f° helning.":                 " The terrain rises %.0f m within 100 m with slopes up to %.0f°.",
+
+	// Historical events
+	"historisk skredhendelse":                 "historical landslide",
+	"historiske skredhendelser":               "historical landslides",
+	"Ingen registrerte skredhendelser":        "No registered landslides",
+	"historisk flomhendelse":                  "historical flood",
+	"historiske flomhendelser":                "historical floods",
+	"Ingen registrerte flomhendelser":         "No registered floods",
+	"registrert kvikkleireskred":              "registered quick clay slide",
+	"registrerte kvikkleireskred":             "registered quick clay slides",
+	"Ingen registrerte kvikkleireskred":       "No registered quick clay slides",
+	"Ingen %s innenfor 1 km.":                 "No %s within 1 km.",
+	"1 %s innenfor 1 km":                      "1 %s within 1 km",
+	"%d %s innenfor 1 km":                     "%d %s within 1 km",
+	"og %d til.":                              "and %d more.",
+	", %d m unna":                             ", %d m away",
+	"ovenfor adressen":                        "above the address",
+	"nedenfor adressen":                       "below the address",
+	" Listen er avkortet etter %d hendelser.": " The list is truncated after %d events.",
+
+	// Event types
+	"Skred fra fast fjell":     "Bedrock slide",
+	"Steinskred":               "Rock slide",
+	"Fjellskred":               "Rock avalanche",
+	"Undervannsskred":          "Submarine slide",
+	"Vått snøskred":            "Wet snow avalanche",
+	"Tørt snøskred":            "Dry snow avalanche",
+	"Sørpeskred":               "Slush flow",
+	"Løssnøskred":              "Loose snow avalanche",
+	"Vått løssnøskred":         "Wet loose snow avalanche",
+	"Tørt løssnøskred":         "Dry loose snow avalanche",
+	"Flakskred":                "Slab avalanche",
+	"Vått flakskred":           "Wet slab avalanche",
+	"Tørt flakskred":           "Dry slab avalanche",
+	"Løsmasseskred":            "Soil slide",
+	"Kvikkleireskred":          "Quick clay slide",
+	"Flomskred":                "Debris flow",
+	"Leirskred":                "Clay slide",
+	"Jordskred":                "Debris slide",
+	"Isnedfall":                "Ice fall",
+	"Skavlfall":                "Cornice fall",
+	"Utglidning":               "Slump",
+	"Skred (type ikke angitt)": "Landslide (type not given)",
+	"Skred (ukjent type)":      "Landslide (unknown type)",
+	"Flom":                     "Flood",
+
+	// Summaries
+	"Adressen har lav risiko for naturfare. Ingen kjente faresoner er registrert her.":                                                      "The address has a low risk of natural hazards. No known hazard zones are registered here.",
+	"Adressen har moderat risiko. Det er registrert noen aktsomhetsområder i nærheten.":                                                     "The address has a moderate risk. Some awareness areas are registered nearby.",
+	"Adressen har høy risiko (score %d/100). Én eller flere naturfaresoner er registrert på dette punktet.":                                 "The address has a high risk (score %d/100). One or more natural hazard zones are registered at this point.",
+	"Adressen har svært høy risiko (score %d/100). Flere alvorlige naturfaresoner er registrert. Vurder å innhente profesjonell vurdering.": "The address has a very high risk (score %d/100). Several serious natural hazard zones are registered. Consider obtaining a professional assessment.",
+	"Høyden (%.1f moh., ±%.1f m) er for usikker til å avgjøre om adressen ligger under %.0f moh.":                                           "The elevation (%.1f m above sea level, ±%.1f m) is too uncertain to tell whether the address is below %.0f m above sea level.",
+	"NVE har ikke kartlagt %s her, så faren for disse er ukjent.":                                                                           "NVE has not mapped %s here, so these hazards are unknown.",
+	"Ingen aktive farevarsler påvirker de registrerte farene på adressen.":                                                                  "No active warnings affect the registered hazards at the address.",
+	"Aktive farevarsler øker risikoen nå (score %d/100). Følg med på varsler fra MET og NVE.":                                               "Active warnings raise the risk now (score %d/100). Keep up with warnings from MET and NVE.",
+
+	// Buildings
+	"bygningen":  "the building",
+	"bygning %s": "building %s",
+	"Deler av %s ligger mer utsatt enn adressepunktet.": "Parts of %s are more exposed than the address point.",
+	"Kunne ikke hente bygningsdata":                     "Could not fetch building data",
+
+	// PDF report
+	"Hvor trygt bor du?":                    "How safe is your home?",
+	"Risikorapport":                         "Risk report",
+	"Side %d":                               "Page %d",
+	"Kommune: %s · Koordinater: %.5f, %.5f": "Municipality: %s · Coordinates: %.5f, %.5f",
+	"Vurderings-ID: %s":                     "Assessment ID: %s",
+	"Generert %s":                           "Generated %s",
+	"Lav risiko":                            "Low risk",
+	"Moderat risiko":                        "Moderate risk",
+	"Høy risiko":                            "High risk",
+	"Svært høy risiko":                      "Very high risk",
+	"Moderat":                               "Moderate",
+	"Svært høy":                             "Very high",
+	"Samlet score 0–100":                    "Overall score 0–100",
+	"Høyde: %.1f moh.":                      "Elevation: %.1f m above sea level",
+	"Helning %.0f°":                         "Slope %.0f°",
+	"Helning %.0f° mot %s":                  "Slope %.0f° facing %s",
+	"terrenget stiger %.0f m innen 100 m":   "terrain rises %.0f m within 100 m",
+	"Risiko nå: %d (%s) — %s":               "Risk now: %d (%s) — %s",
+	"Klimascenario %s: %d (%s)":             "Climate scenario %s: %d (%s)",
+	"Bygningen: verste punkt %d (%s)":       "The building: worst point %d (%s)",
+	"Bygning %s: verste punkt %d (%s)":      "Building %s: worst point %d (%s)",
+	"laveste hjørne %.1f moh.":              "lowest corner %.1f m above sea level",
+	"Farekart":                              "Hazard map",
+	"Kartet kunne ikke hentes.":             "The map could not be fetched.",
+	"Kartgrunnlag: Kartverket. Faresoner og aktsomhetsområder: NVE. Adressen er markert med rød prikk. Kartet viser omtrent %d m til hver side.": "Base map: Kartverket. Hazard zones and awareness areas: NVE. The address is marked with a red dot. The map shows about %d m to each side.",
+	"Farer":                          "Hazards",
+	"Ikke kartlagt":                  "Not mapped",
+	"Aktsomhetskart":                 "Awareness map",
+	"Estimat":                        "Estimate",
+	"Scenario %s (%s): %d bygninger": "Scenario %s (%s): %d buildings",
+	"Historiske hendelser":           "Historical events",
+	"Ingen registrerte hendelser i nærheten.":          "No registered events nearby.",
+	"%d hendelser, %d med bygningsskade, %d omkommet.": "%d events, %d with building damage, %d killed.",
+	"Dato":                 "Date",
+	"Avstand":              "Distance",
+	"Skade":                "Damage",
+	"ukjent dato":          "unknown date",
+	"bygning":              "building",
+	"veg":                  "road",
+	"%d omkommet":          "%d killed",
+	"+ %d flere hendelser": "+ %d more events",
+	"Søket nådde grensen for antall hendelser; listen kan være ufullstendig.": "The search reached its event limit; the list may be incomplete.",
+	"Farevarsler":               "Warnings",
+	"Ingen aktive farevarsler.": "No active warnings.",
+	"Flomvarsel":                "Flood warning",
+	"Jordskredvarsel":           "Landslide warning",
+	"Snøskredvarsel":            "Avalanche warning",
+	"nivå %d":                   "level %d",
+	"Datakilder":                "Data sources",
+	"Tidspunktene er når dataene ble hentet fra kilden. Svar fra hurtigbufferen har tidspunktet fra den opprinnelige hentingen.": "The times are when the data was fetched from the source. Cached responses carry the time of the original fetch.",
+	"Forbehold": "Disclaimer",
+	"Kun veiledende — erstatter ikke profesjonell vurdering.": "For guidance only — does not replace a professional assessment.",
+	"Rapporten bygger på åpne data fra NVE, Kartverket og MET slik de var på tidspunktene over. NVE-kartene dekker ikke hele landet; at en adresse ligger utenfor kartlagte soner betyr ikke nødvendigvis at det ikke er fare der.": "The report is based on open data from NVE, Kartverket and MET as it was at the times above. NVE's maps do not cover the whole country; an address outside mapped zones is not necessarily free of hazards.",
+
+	// Permalinks
+	"Sjekk naturfare for din adresse": "Check natural hazards for your address",
+	"%s: %s (%d av 100)":              "%s: %s (%d of 100)",
+
+	// Municipality overview
+	"Soner som krysser kommunegrensen telles med i kommunen der tyngdepunktet ligger, med hele arealet.": "Zones crossing the municipal border are counted in the municipality containing their centroid, with their whole area.",
+
+	// Errors
+	"Kunne ikke hente data":                         "Could not fetch data",
+	"Kunne ikke hente adresser":                     "Could not fetch addresses",
+	"Fant ikke data":                                "No data found",
+	"Datakilden er overbelastet, prøv igjen senere": "The data source is overloaded, try again later",
+	"Ugyldig svar fra datakilden":                   "Invalid response from the data source",
+	"Datakilden svarte ikke i tide":                 "The data source did not respond in time",
+}
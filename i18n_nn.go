@@ -0,0 +1,184 @@
+package main
+
+// catalogNN translates generated texts to Nynorsk. Texts that read the same
+// in both written standards are left out.
+var catalogNN = map[string]string{
+	// Hazard names and awareness areas
+	"Flomsoner":            "Flaumsoner",
+	"Flomaktsomhet":        "Flaumaktsemd",
+	"Flomaktsomhetsområde": "Aktsemdområde for flaum",
+	"Jord- og flomskred":   "Jord- og flaumskred",
+	"Aktsomhetsområde for jord- og flomskred": "Aktsemdområde for jord- og flaumskred",
+	"Aktsomhetsområde for snøskred":           "Aktsemdområde for snøskred",
+	"Aktsomhetsområde for steinsprang":        "Aktsemdområde for steinsprang",
+	"Flomscreening (estimat)":                 "Flaumscreening (estimat)",
+	"Historiske flomhendelser":                "Historiske flaumhendingar",
+	"Historiske skredhendelser":               "Historiske skredhendingar",
+	"Flomsoner (200-år)":                      "Flaumsoner (200-år)",
+
+	// Flood zones
+	"10-årsflom":               "10-årsflaum",
+	"20-årsflom":               "20-årsflaum",
+	"50-årsflom":               "50-årsflaum",
+	"100-årsflom":              "100-årsflaum",
+	"200-årsflom":              "200-årsflaum",
+	"Ikke i kartlagt flomsone": "Ikkje i kartlagd flaumsone",
+	"Ikke flomsonekartlagt":    "Ikkje flaumsonekartlagt",
+	"Adressen ligger i en kartlagt flomsone (%s). Risiko for oversvømmelse ved ekstremvær.":                     "Adressa ligg i ei kartlagd flaumsone (%s). Risiko for oversvømming ved ekstremvêr.",
+	"Området er flomsonekartlagt av NVE, og adressen ligger utenfor flomsonene.":                                "Området er flaumsonekartlagt av NVE, og adressa ligg utanfor flaumsonene.",
+	"NVE har ikke flomsonekartlagt dette området. Score 0 betyr at faren er ukjent, ikke at den er fraværende.": "NVE har ikkje flaumsonekartlagt dette området. Score 0 tyder at faren er ukjend, ikkje at han er fråverande.",
+
+	// Landslide hazard zones
+	"Ikke i kartlagt faresone": "Ikkje i kartlagd faresone",
+	"Ikke faresonekartlagt":    "Ikkje faresonekartlagt",
+	"Adressen ligger i en kartlagt faresone for skred med årlig sannsynlighet 1/%d. Oppfyller ikke TEK17 sikkerhetsklasse %s.":   "Adressa ligg i ei kartlagd faresone for skred med årleg sannsyn 1/%d. Oppfyller ikkje TEK17 tryggleiksklasse %s.",
+	"Området er faresonekartlagt for skred av NVE, og adressen ligger utenfor faresonene.":                                       "Området er faresonekartlagt for skred av NVE, og adressa ligg utanfor faresonene.",
+	"NVE har ikke faresonekartlagt skred i dette området. Se aktsomhetskartene for snøskred, steinsprang og jord- og flomskred.": "NVE har ikkje faresonekartlagt skred i dette området. Sjå aktsemdkarta for snøskred, steinsprang og jord- og flaumskred.",
+
+	// Awareness maps
+	"Adressen ligger i %s.":   "Adressa ligg i %s.",
+	"Ikke i aktsomhetsområde": "Ikkje i aktsemdområde",
+	"Innenfor %s-sone":        "Innanfor %s-sone",
+
+	// Zone attributes
+	"utløsningsområde":                                          "utløysingsområde",
+	" Beregnet vanndybde: %.1f m.":                              " Berekna vassdjupn: %.1f m.",
+	" Nominell årlig sannsynlighet: 1/%d.":                      " Nominelt årleg sannsyn: 1/%d.",
+	" Kartleggingen er over 20 år gammel og kan være utdatert.": " Kartlegginga er over 20 år gammal og kan vere utdatert.",
+
+	// Quick clay; hazard grades come from the NVE data
+	"Høy":                             "Høg",
+	"Hoy":                             "Høg",
+	"Lav":                             "Låg",
+	"Ukjent":                          "Ukjend",
+	"Aktsomhetsområde for kvikkleire": "Aktsemdområde for kvikkleire",
+	"Ikke i kvikkleireområde":         "Ikkje i kvikkleireområde",
+	"Adressen ligger i område med kartlagt kvikkleirefare.":          "Adressa ligg i område med kartlagd kvikkleirefare.",
+	"Adressen ligger i et generelt aktsomhetsområde for kvikkleire.": "Adressa ligg i eit generelt aktsemdområde for kvikkleire.",
+
+	// Storm surge
+	"Ingen stormflodata": "Ingen data om stormflo",
+	"Over stormflonivå":  "Over nivået for stormflo",
+	"Ingen stormflodata tilgjengelig for denne kommunen.":                                                                     "Ingen stormflodata tilgjengeleg for denne kommunen.",
+	"Adressen ligger høyt nok til at stormflo neppe er en trussel.":                                                           "Adressa ligg høgt nok til at stormflo neppe er ein trussel.",
+	" I verste scenario (%s, %s) berøres %d bygninger i kommunen, tilsvarende %.1f %% av adressene.":                          " I verste scenario (%s, %s) vert %d bygningar i kommunen råka, tilsvarande %.1f %% av adressene.",
+	" Høyden er usikker (±%.1f m), så adressen kan ligge både over og under denne grensen.":                                   " Høgda er usikker (±%.1f m), så adressa kan liggje både over og under denne grensa.",
+	"Lav kystbeliggenhet (%.1f moh.)":                                                                                         "Låg kystplassering (%.1f moh.)",
+	"Lav kystbeliggenhet, usikker høyde (%.1f moh.)":                                                                          "Låg kystplassering, usikker høgd (%.1f moh.)",
+	"Kystnær beliggenhet (%.1f moh.)":                                                                                         "Kystnær plassering (%.1f moh.)",
+	"Kystnær beliggenhet, usikker høyde (%.1f moh.)":                                                                          "Kystnær plassering, usikker høgd (%.1f moh.)",
+	"Adressen ligger på bare %.1f moh. i en kystkommune med stormflorisiko. Kan bli berørt ved ekstreme stormflosituasjoner.": "Adressa ligg på berre %.1f moh. i ein kystkommune med stormflorisiko. Kan bli råka ved ekstreme stormflosituasjonar.",
+	"Adressen ligger på omtrent %.1f moh. i en kystkommune med stormflorisiko, nær grensen på 3 moh.":                         "Adressa ligg på omtrent %.1f moh. i ein kystkommune med stormflorisiko, nær grensa på 3 moh.",
+	"Adressen ligger på %.1f moh. i en kystkommune. Moderat risiko for stormflo.":                                             "Adressa ligg på %.1f moh. i ein kystkommune. Moderat risiko for stormflo.",
+	"Adressen ligger på omtrent %.1f moh. i en kystkommune, nær grensen på 10 moh.":                                           "Adressa ligg på omtrent %.1f moh. i ein kystkommune, nær grensa på 10 moh.",
+
+	// Climate projections
+	"Flomaktsomhetsområde med %.0f %% klimapåslag":   "Aktsemdområde for flaum med %.0f %% klimapåslag",
+	"Effektivt %.1f moh. med %.2f m havnivåstigning": "Effektivt %.1f moh. med %.2f m havnivåstiging",
+	"Flomsonekartene viser dagens situasjon. Med %.0f %% klimapåslag kan flomsonen utvides, men punktet er ikke kartlagt som flomutsatt i dag.":                 "Flaumsonekarta viser dagens situasjon. Med %.0f %% klimapåslag kan flaumsona verte utvida, men punktet er ikkje kartlagt som flaumutsett i dag.",
+	"Med NVEs klimapåslag på %.0f %% i flomvannføring kan dagens %s opptre omtrent like ofte som en %s.":                                                        "Med klimapåslaget til NVE på %.0f %% i flaumvassføring kan dagens %s opptre omtrent like ofte som ein %s.",
+	"Økt flomvannføring gjør flom i aktsomhetsområdet mer sannsynlig.":                                                                                          "Auka flaumvassføring gjer flaum i aktsemdområdet meir sannsynleg.",
+	"Landsgjennomsnittet i Kartverkets framskrivninger er omtrent %.2f m havnivåstigning innen %s. Adressen ligger da effektivt %.1f m over dagens middelvann.": "Landsgjennomsnittet i framskrivingane til Kartverket er omtrent %.2f m havnivåstiging innan %s. Adressa ligg då effektivt %.1f m over dagens middelvatn.",
+	"Grovt anslag: havnivåstigningen er et landsgjennomsnitt og klimapåslaget er forenklet per fylke, ikke beregnet for kommunen.":                              "Grovt anslag: havnivåstiginga er eit landsgjennomsnitt og klimapåslaget er forenkla per fylke, ikkje rekna ut for kommunen.",
+
+	// Flood screening
+	"Estimert flomfare ut fra avstand og høyde over nærmeste elv eller innsjø":                                                              "Estimert flaumfare ut frå avstand og høgd over nærmaste elv eller innsjø",
+	"Høyden på adressen er ukjent, så flomfaren kan ikke anslås.":                                                                           "Høgda på adressa er ukjend, så flaumfaren kan ikkje anslåast.",
+	"Ingen elv eller innsjø innen %d m. Estimatet erstatter ikke flomsonekart.":                                                             "Ingen elv eller innsjø innan %d m. Estimatet erstattar ikkje flaumsonekart.",
+	"Nærmeste %s er %d m unna, men høyden over vannflaten er ukjent.":                                                                       "Nærmaste %s er %d m unna, men høgda over vassflata er ukjend.",
+	"Nærmeste %s er %d m unna, og adressen ligger %.1f m over vannflaten. Dette er et grovt estimat fra terrengdata, ikke et flomsonekart.": "Nærmaste %s er %d m unna, og adressa ligg %.1f m over vassflata. Dette er eit grovt estimat frå terrengdata, ikkje eit flaumsonekart.",
+	" Høyden er usikker (±%.1f m), så adressen kan ligge mindre enn %.0f m over vannflaten.":                                                " Høgda er usikker (±%.1f m), så adressa kan liggje mindre enn %.0f m over vassflata.",
+
+	// Terrain
+	" Terrenget innen 100 m er flatt (maks %.0f° helning), noe som reduserer faren.": " Terrenget innan 100 m er flatt (maks %.0f° helling), noko som reduserer faren.",
+	" Terrenget stiger %.0f m innen 100 m med opptil %.0f° helning.":                 " Terrenget stig %.0f m innan 100 m med opptil %.0f° helling.",
+
+	// Historical events
+	"historisk skredhendelse":                 "historisk skredhending",
+	"historiske skredhendelser":               "historiske skredhendingar",
+	"Ingen registrerte skredhendelser":        "Ingen registrerte skredhendingar",
+	"historisk flomhendelse":                  "historisk flaumhending",
+	"historiske flomhendelser":                "historiske flaumhendingar",
+	"Ingen registrerte flomhendelser":         "Ingen registrerte flaumhendingar",
+	"Ingen %s innenfor 1 km.":                 "Ingen %s innanfor 1 km.",
+	"1 %s innenfor 1 km":                      "1 %s innanfor 1 km",
+	"%d %s innenfor 1 km":                     "%d %s innanfor 1 km",
+	"ovenfor adressen":                        "ovanfor adressa",
+	"nedenfor adressen":                       "nedanfor adressa",
+	" Listen er avkortet etter %d hendelser.": " Lista er avkorta etter %d hendingar.",
+
+	// Event types
+	"Skred fra fast fjell":     "Skred frå fast fjell",
+	"Flomskred":                "Flaumskred",
+	"Skred (type ikke angitt)": "Skred (type ikkje oppgitt)",
+	"Skred (ukjent type)":      "Skred (ukjend type)",
+	"Flom":                     "Flaum",
+	"Undervannsskred":          "Undervassskred",
+	"Løssnøskred":              "Laussnøskred",
+	"Vått løssnøskred":         "Vått laussnøskred",
+	"Tørt løssnøskred":         "Tørt laussnøskred",
+	"Løsmasseskred":            "Lausmasseskred",
+	"Utglidning":               "Utgliding",
+
+	// Summaries
+	"Adressen har lav risiko for naturfare. Ingen kjente faresoner er registrert her.":                                                      "Adressa har låg risiko for naturfare. Ingen kjende faresoner er registrerte her.",
+	"Adressen har moderat risiko. Det er registrert noen aktsomhetsområder i nærheten.":                                                     "Adressa har moderat risiko. Det er registrert nokre aktsemdområde i nærleiken.",
+	"Adressen har høy risiko (score %d/100). Én eller flere naturfaresoner er registrert på dette punktet.":                                 "Adressa har høg risiko (score %d/100). Éi eller fleire naturfaresoner er registrerte på dette punktet.",
+	"Adressen har svært høy risiko (score %d/100). Flere alvorlige naturfaresoner er registrert. Vurder å innhente profesjonell vurdering.": "Adressa har svært høg risiko (score %d/100). Fleire alvorlege naturfaresoner er registrerte. Vurder å hente inn profesjonell vurdering.",
+	"Høyden (%.1f moh., ±%.1f m) er for usikker til å avgjøre om adressen ligger under %.0f moh.":                                           "Høgda (%.1f moh., ±%.1f m) er for usikker til å avgjere om adressa ligg under %.0f moh.",
+	"NVE har ikke kartlagt %s her, så faren for disse er ukjent.":                                                                           "NVE har ikkje kartlagt %s her, så faren for desse er ukjend.",
+	"Ingen aktive farevarsler påvirker de registrerte farene på adressen.":                                                                  "Ingen aktive farevarsel påverkar dei registrerte farane på adressa.",
+	"Aktive farevarsler øker risikoen nå (score %d/100). Følg med på varsler fra MET og NVE.":                                               "Aktive farevarsel aukar risikoen no (score %d/100). Følg med på varsel frå MET og NVE.",
+
+	// Buildings
+	"Deler av %s ligger mer utsatt enn adressepunktet.": "Delar av %s ligg meir utsett enn adressepunktet.",
+	"Kunne ikke hente bygningsdata":                     "Kunne ikkje hente bygningsdata",
+
+	// PDF report
+	"Kommune: %s · Koordinater: %.5f, %.5f": "Kommune: %s · Koordinatar: %.5f, %.5f",
+	"Lav risiko":                            "Låg risiko",
+	"Høy risiko":                            "Høg risiko",
+	"Svært høy risiko":                      "Svært høg risiko",
+	"Svært høy":                             "Svært høg",
+	"Samlet score 0–100":                    "Samla score 0–100",
+	"Høyde: %.1f moh.":                      "Høgd: %.1f moh.",
+	"Helning %.0f°":                         "Helling %.0f°",
+	"Helning %.0f° mot %s":                  "Helling %.0f° mot %s",
+	"terrenget stiger %.0f m innen 100 m":   "terrenget stig %.0f m innan 100 m",
+	"Risiko nå: %d (%s) — %s":               "Risiko no: %d (%s) — %s",
+	"laveste hjørne %.1f moh.":              "lågaste hjørne %.1f moh.",
+	"Kartet kunne ikke hentes.":             "Kartet kunne ikkje hentast.",
+	"Kartgrunnlag: Kartverket. Faresoner og aktsomhetsområder: NVE. Adressen er markert med rød prikk. Kartet viser omtrent %d m til hver side.": "Kartgrunnlag: Kartverket. Faresoner og aktsemdområde: NVE. Adressa er markert med raud prikk. Kartet viser omtrent %d m til kvar side.",
+	"Ikke kartlagt":                                    "Ikkje kartlagt",
+	"Aktsomhetskart":                                   "Aktsemdkart",
+	"Scenario %s (%s): %d bygninger":                   "Scenario %s (%s): %d bygningar",
+	"Historiske hendelser":                             "Historiske hendingar",
+	"Ingen registrerte hendelser i nærheten.":          "Ingen registrerte hendingar i nærleiken.",
+	"%d hendelser, %d med bygningsskade, %d omkommet.": "%d hendingar, %d med bygningsskade, %d omkomne.",
+	"ukjent dato":                                      "ukjend dato",
+	"%d omkommet":                                      "%d omkomne",
+	"+ %d flere hendelser":                             "+ %d fleire hendingar",
+	"Søket nådde grensen for antall hendelser; listen kan være ufullstendig.": "Søket nådde grensa for talet på hendingar; lista kan vere ufullstendig.",
+	"Flomvarsel":                "Flaumvarsel",
+	"Farevarsler":               "Farevarsel",
+	"Ingen aktive farevarsler.": "Ingen aktive farevarsel.",
+	"Datakilder":                "Datakjelder",
+	"Tidspunktene er når dataene ble hentet fra kilden. Svar fra hurtigbufferen har tidspunktet fra den opprinnelige hentingen.": "Tidspunkta er når dataa vart henta frå kjelda. Svar frå mellomlageret har tidspunktet frå den opphavlege hentinga.",
+	"Kun veiledende — erstatter ikke profesjonell vurdering.":                                                                    "Berre rettleiande — erstattar ikkje profesjonell vurdering.",
+	"Rapporten bygger på åpne data fra NVE, Kartverket og MET slik de var på tidspunktene over. NVE-kartene dekker ikke hele landet; at en adresse ligger utenfor kartlagte soner betyr ikke nødvendigvis at det ikke er fare der.": "Rapporten byggjer på opne data frå NVE, Kartverket og MET slik dei var på tidspunkta over. NVE-karta dekkjer ikkje heile landet; at ei adresse ligg utanfor kartlagde soner, tyder ikkje nødvendigvis at det ikkje er fare der.",
+
+	// Permalinks
+	"Hvor trygt bor du?":              "Kor trygt bur du?",
+	"Sjekk naturfare for din adresse": "Sjekk naturfare for adressa di",
+
+	// Municipality overview
+	"Soner som krysser kommunegrensen telles med i kommunen der tyngdepunktet ligger, med hele arealet.": "Soner som kryssar kommunegrensa blir talde med i kommunen der tyngdepunktet ligg, med heile arealet.",
+
+	// Errors
+	"Kunne ikke hente data":                         "Kunne ikkje hente data",
+	"Kunne ikke hente adresser":                     "Kunne ikkje hente adresser",
+	"Fant ikke data":                                "Fann ikkje data",
+	"Datakilden er overbelastet, prøv igjen senere": "Datakjelda er overbelasta, prøv igjen seinare",
+	"Ugyldig svar fra datakilden":                   "Ugyldig svar frå datakjelda",
+	"Datakilden svarte ikke i tide":                 "Datakjelda svarte ikkje i tide",
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Municipality aggregation works in UTM zone 33N (EPSG:25833), the national
+// projection, so areas come out in square meters without reprojection.
+const (
+	kommuneInfoURL  = "https://ws.geonorge.no/kommuneinfo/v1/kommuner"
+	kommuneSR       = "25833"
+	kommuneCacheTTL = 24 * time.Hour
+
+	// kommuneLayerPageSize and kommuneLayerMaxPages bound each NVE layer
+	// fetch; polygons are generalized by kommuneGeneralizeM to keep pages
+	// under cachedGet's size limit.
+	kommuneLayerPageSize = 200
+	kommuneLayerMaxPages = 50
+	kommuneGeneralizeM   = 10
+
+	// The address API returns at most 10 000 hits per search.
+	kommuneAddressPageSize = 1000
+	kommuneAddressMaxPages = 10
+)
+
+// kommuneZone is an NVE layer aggregated per municipality.
+type kommuneZone struct {
+	HazardID string
+	Name     string
+	Service  nveService
+}
+
+// kommuneZones uses the widest layer of each hazard: the 200-year flood zone
+// contains the more frequent ones, and the 1000-year landslide hazard zone
+// is the TEK17 S2 limit that applies to ordinary housing.
+var kommuneZones = []kommuneZone{
+	{"flood_zones", "Flomsoner (200-år)", svcFlood200yr},
+	{"flood_awareness", "Flomaktsomhet", svcFloodAwareness},
+	{"landslide", "Jord- og flomskred", svcLandslide},
+	{"quick_clay", "Kvikkleire", svcQuickClayDetail},
+	{"avalanche", "Snøskred", svcAvalanche},
+	{"rock_fall", "Steinsprang", svcRockFall},
+	{"combined_hazard", "Skredfaresoner (1000-år)", svcHazardZone1000yr},
+}
+
+// multiPolygon is a GeoJSON MultiPolygon's coordinates.
+type multiPolygon [][][][2]float64
+
+type kommuneOmrade struct {
+	Kommunenavn string `json:"kommunenavn"`
+	Omrade      struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"omrade"`
+}
+
+// kommuneBoundary is a municipality outline in UTM 33N.
+type kommuneBoundary struct {
+	name  string
+	rings [][][2]float64 // all rings of all polygons
+	bbox  [4]float64     // minX, minY, maxX, maxY
+	area  float64        // square meters
+}
+
+func (b kommuneBoundary) contains(x, y float64) bool {
+	return ringsContain(b.rings, x, y)
+}
+
+// getKommuneBoundary fetches the municipality outline from Kartverket.
+func getKommuneBoundary(ctx context.Context, cache *Cache, knr string) (kommuneBoundary, error) {
+	u := fmt.Sprintf("%s/%s/omrade?utkoordsys=%s", kommuneInfoURL, knr, kommuneSR)
+	data, err := cachedGet(ctx, cache, u, kommuneCacheTTL)
+	if err != nil {
+		return kommuneBoundary{}, fmt.Errorf("kommuneinfo: %w", err)
+	}
+
+	var result kommuneOmrade
+	if err := json.Unmarshal(data, &result); err != nil {
+		return kommuneBoundary{}, fmt.Errorf("kommuneinfo decode: %w", err)
+	}
+
+	var mp multiPolygon
+	switch result.Omrade.Type {
+	case "Polygon":
+		var poly [][][2]float64
+		if err := json.Unmarshal(result.Omrade.Coordinates, &poly); err != nil {
+			return kommuneBoundary{}, fmt.Errorf("kommuneinfo geometry: %w", err)
+		}
+		mp = multiPolygon{poly}
+	case "MultiPolygon":
+		if err := json.Unmarshal(result.Omrade.Coordinates, &mp); err != nil {
+			return kommuneBoundary{}, fmt.Errorf("kommuneinfo geometry: %w", err)
+		}
+	}
+
+	// GeoJSON polygons list the outer ring first, then holes.
+	b := kommuneBoundary{name: result.Kommunenavn}
+	for _, poly := range mp {
+		for i, ring := range poly {
+			if i == 0 {
+				b.area += math.Abs(shoelace(ring))
+			} else {
+				b.area -= math.Abs(shoelace(ring))
+			}
+		}
+		b.rings = append(b.rings, poly...)
+	}
+	if len(b.rings) == 0 {
+		return b, fmt.Errorf("kommuneinfo: no boundary for %s", knr)
+	}
+	b.bbox = ringsBBox(b.rings)
+	return b, nil
+}
+
+// layerFeature is an ArcGIS feature with polygon or point geometry.
+type layerFeature struct {
+	Geometry *struct {
+		Rings [][][2]float64 `json:"rings"`
+		X     *float64       `json:"x"`
+		Y     *float64       `json:"y"`
+	} `json:"geometry"`
+}
+
+type layerResponse struct {
+	Features              []layerFeature `json:"features"`
+	ExceededTransferLimit bool           `json:"exceededTransferLimit"`
+}
+
+// queryLayerEnvelope pages through the features of an ArcGIS layer that
+// intersect bbox, with geometry in UTM 33N. queryURL is the layer's query
+// endpoint. The bool result reports whether paging stopped at maxPages.
+func queryLayerEnvelope(ctx context.Context, cache *Cache, queryURL string, bbox [4]float64, maxPages int) ([]layerFeature, bool, error) {
+	var features []layerFeature
+	for page := range maxPages {
+		params := url.Values{
+			"geometry":           {fmt.Sprintf("%f,%f,%f,%f", bbox[0], bbox[1], bbox[2], bbox[3])},
+			"geometryType":       {"esriGeometryEnvelope"},
+			"inSR":               {kommuneSR},
+			"outSR":              {kommuneSR},
+			"spatialRel":         {"esriSpatialRelIntersects"},
+			"outFields":          {"OBJECTID"},
+			"returnGeometry":     {"true"},
+			"maxAllowableOffset": {strconv.Itoa(kommuneGeneralizeM)},
+			"orderByFields":      {"OBJECTID"},
+			"resultOffset":       {strconv.Itoa(page * kommuneLayerPageSize)},
+			"resultRecordCount":  {strconv.Itoa(kommuneLayerPageSize)},
+			"f":                  {"json"},
+		}
+		data, err := cachedGet(ctx, cache, queryURL+"?"+params.Encode(), kommuneCacheTTL)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var result layerResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, false, fmt.Errorf("decode: %w", err)
+		}
+		features = append(features, result.Features...)
+		if !result.ExceededTransferLimit {
+			return features, false, nil
+		}
+	}
+	return features, true, nil
+}
+
+type addressPage struct {
+	Metadata struct {
+		TotaltAntallTreff int `json:"totaltAntallTreff"`
+	} `json:"metadata"`
+	Adresser []struct {
+		Representasjonspunkt struct {
+			Lat float64 `json:"lat"` // northing in UTM 33N
+			Lon float64 `json:"lon"` // easting in UTM 33N
+		} `json:"representasjonspunkt"`
+	} `json:"adresser"`
+}
+
+// getKommuneAddresses returns the representation points of all addresses in
+// the municipality, in UTM 33N, sorted by easting. The bool result reports
+// whether the address API's hit limit cut the list short.
+func getKommuneAddresses(ctx context.Context, cache *Cache, knr string) ([][2]float64, bool, error) {
+	var points [][2]float64
+	total := 0
+	for page := range kommuneAddressMaxPages {
+		params := url.Values{
+			"kommunenummer": {knr},
+			"treffPerSide":  {strconv.Itoa(kommuneAddressPageSize)},
+			"side":          {strconv.Itoa(page)},
+			"utkoordsys":    {kommuneSR},
+			"filtrer":       {"adresser.representasjonspunkt,metadata.totaltAntallTreff"},
+		}
+		data, err := cachedGet(ctx, cache, geonorgeSearchURL+"?"+params.Encode(), kommuneCacheTTL)
+		if err != nil {
+			return nil, false, fmt.Errorf("addresses: %w", err)
+		}
+
+		var result addressPage
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, false, fmt.Errorf("addresses decode: %w", err)
+		}
+		total = result.Metadata.TotaltAntallTreff
+		for _, a := range result.Adresser {
+			points = append(points, [2]float64{a.Representasjonspunkt.Lon, a.Representasjonspunkt.Lat})
+		}
+		if len(result.Adresser) < kommuneAddressPageSize || len(points) >= total {
+			break
+		}
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i][0] < points[j][0] })
+	return points, len(points) < total, nil
+}
+
+// countKommuneAddresses returns the number of addresses in the municipality.
+func countKommuneAddresses(ctx context.Context, cache *Cache, knr string) (int, error) {
+	params := url.Values{
+		"kommunenummer": {knr},
+		"treffPerSide":  {"1"},
+		"filtrer":       {"metadata.totaltAntallTreff"},
+	}
+	data, err := cachedGet(ctx, cache, geonorgeSearchURL+"?"+params.Encode(), kommuneCacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf("address count: %w", err)
+	}
+
+	var result addressPage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, fmt.Errorf("address count decode: %w", err)
+	}
+	return result.Metadata.TotaltAntallTreff, nil
+}
+
+// aggregateKommune builds the municipality risk overview. The result is
+// cached as a whole per language, on top of the per-request caching of
+// upstream data.
+func aggregateKommune(ctx context.Context, cache *Cache, knr string, loc localizer) (*KommuneRisk, error) {
+	key := "kommune:" + knr + ":" + loc.Lang
+	if data, ok := cache.Get(key); ok {
+		var cached KommuneRisk
+		if err := json.Unmarshal(data, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	boundary, err := getKommuneBoundary(ctx, cache, knr)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &KommuneRisk{
+		Kommunenummer:    knr,
+		Kommunenavn:      boundary.name,
+		AreaKm2:          round1(boundary.area / 1e6),
+		Zones:            make([]ZoneExposure, len(kommuneZones)),
+		ZonesNote:        loc.T("Soner som krysser kommunegrensen telles med i kommunen der tyngdepunktet ligger, med hele arealet."),
+		HistoricalEvents: make(map[string]int),
+		GeneratedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		addresses [][2]float64
+		addrErr   error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		addresses, k.AddressesTruncated, addrErr = getKommuneAddresses(ctx, cache, knr)
+	}()
+
+	zonePolygons := make([][][][][2]float64, len(kommuneZones))
+	for i, z := range kommuneZones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ze := ZoneExposure{HazardID: z.HazardID, Name: loc.T(z.Name)}
+			queryURL := fmt.Sprintf("%s/%d/query", z.Service.BaseURL, z.Service.Layer)
+			features, truncated, err := queryLayerEnvelope(ctx, cache, queryURL, boundary.bbox, kommuneLayerMaxPages)
+			if err != nil {
+				log.Printf("kommune %s %s error: %v", knr, z.HazardID, err)
+				ze.ErrorCode = errorCode(err)
+				ze.Error = loc.T(hazardErrorMessages[ze.ErrorCode])
+				k.Zones[i] = ze
+				return
+			}
+			ze.Truncated = truncated
+
+			var area float64
+			for _, f := range features {
+				if f.Geometry == nil || len(f.Geometry.Rings) == 0 || len(f.Geometry.Rings[0]) == 0 {
+					continue
+				}
+				// Polygons are attributed to the municipality containing the
+				// centroid of their outer ring; those crossing the border are
+				// counted whole (see KommuneRisk.ZonesNote).
+				c := ringCentroid(f.Geometry.Rings[0])
+				if !boundary.contains(c[0], c[1]) {
+					continue
+				}
+				area += ringsArea(f.Geometry.Rings)
+				zonePolygons[i] = append(zonePolygons[i], f.Geometry.Rings)
+			}
+			ze.AreaKm2 = math.Round(area/1e4) / 100
+			k.Zones[i] = ze
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		entries, err := getStormSurge(ctx, cache, knr)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			log.Printf("kommune %s stormflo error: %v", knr, err)
+			k.StormSurgeError = loc.T("Kunne ikke hente data")
+			return
+		}
+		k.StormSurge = stormSurgeScenarios(entries)
+	}()
+
+	for _, src := range []eventSource{sourceSkred, sourceFlood, sourceQuickClay} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			features, truncated, err := queryLayerEnvelope(ctx, cache, src.URL, boundary.bbox, kommuneLayerMaxPages)
+			if err != nil {
+				log.Printf("kommune %s %s error: %v", knr, src.ID, err)
+				mu.Lock()
+				if k.HistoricalEventsErrors == nil {
+					k.HistoricalEventsErrors = make(map[string]string)
+				}
+				k.HistoricalEventsErrors[src.ID] = loc.T(hazardErrorMessages[errorCode(err)])
+				mu.Unlock()
+				return
+			}
+			n := 0
+			for _, f := range features {
+				if f.Geometry != nil && f.Geometry.X != nil && f.Geometry.Y != nil && boundary.contains(*f.Geometry.X, *f.Geometry.Y) {
+					n++
+				}
+			}
+			mu.Lock()
+			k.HistoricalEvents[src.ID] = n
+			k.HistoricalEventsTruncated = k.HistoricalEventsTruncated || truncated
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if addrErr != nil {
+		log.Printf("kommune %s addresses error: %v", knr, addrErr)
+		k.AddressesError = loc.T("Kunne ikke hente adresser")
+	}
+	k.Addresses = len(addresses)
+	for i := range k.Zones {
+		z := &k.Zones[i]
+		if k.AreaKm2 > 0 {
+			z.AreaShare = math.Round(z.AreaKm2/k.AreaKm2*1000) / 1000
+		}
+		if addrErr == nil && z.Error == "" {
+			z.Addresses = countAddressesInPolygons(addresses, zonePolygons[i])
+		}
+	}
+
+	// Partial results are not cached, so a transient failure is retried.
+	complete := addrErr == nil && k.StormSurgeError == "" && len(k.HistoricalEventsErrors) == 0
+	for _, z := range k.Zones {
+		complete = complete && z.Error == ""
+	}
+	if data, err := json.Marshal(k); err == nil && complete {
+		cache.Set(key, data, kommuneCacheTTL)
+	}
+	return k, nil
+}
+
+// stormSurgeScenarios converts consequence API entries to the response type.
+func stormSurgeScenarios(entries []stormfloEntry) []StormSurgeScenario {
+	scenarios := make([]StormSurgeScenario, 0, len(entries))
+	for _, e := range entries {
+		scenarios = append(scenarios, StormSurgeScenario{
+			Code:      e.Code,
+			Year:      e.Year,
+			Buildings: e.BygningTotal,
+		})
+	}
+	return scenarios
+}
+
+// countAddressesInPolygons counts addresses inside at least one polygon.
+// points must be sorted by x.
+func countAddressesInPolygons(points [][2]float64, polygons [][][][2]float64) int {
+	inside := make(map[int]bool)
+	for _, rings := range polygons {
+		bb := ringsBBox(rings)
+		start := sort.Search(len(points), func(i int) bool { return points[i][0] >= bb[0] })
+		for i := start; i < len(points) && points[i][0] <= bb[2]; i++ {
+			p := points[i]
+			if inside[i] || p[1] < bb[1] || p[1] > bb[3] {
+				continue
+			}
+			if ringsContain(rings, p[0], p[1]) {
+				inside[i] = true
+			}
+		}
+	}
+	return len(inside)
+}
+
+// ringsContain reports whether a point is inside a set of rings using the
+// even-odd rule, so holes are excluded regardless of ring orientation.
+func ringsContain(rings [][][2]float64, x, y float64) bool {
+	inside := false
+	for _, ring := range rings {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// ringsArea returns the area of an ArcGIS polygon. Outer rings run
+// clockwise and holes counterclockwise, so the signed ring areas sum to the
+// polygon's area.
+func ringsArea(rings [][][2]float64) float64 {
+	var total float64
+	for _, ring := range rings {
+		total += shoelace(ring)
+	}
+	return math.Abs(total)
+}
+
+// shoelace returns the signed area of a ring, positive when clockwise.
+func shoelace(ring [][2]float64) float64 {
+	var s float64
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		s += (ring[j][0] + ring[i][0]) * (ring[j][1] - ring[i][1])
+	}
+	return s / 2
+}
+
+// ringCentroid returns the area centroid of a ring, or its first vertex if
+// the ring has no area. Coordinates are taken relative to the first vertex
+// to keep precision with UTM-sized values.
+func ringCentroid(ring [][2]float64) [2]float64 {
+	o := ring[0]
+	var a, cx, cy float64
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xj, yj := ring[j][0]-o[0], ring[j][1]-o[1]
+		xi, yi := ring[i][0]-o[0], ring[i][1]-o[1]
+		cross := xj*yi - xi*yj
+		a += cross
+		cx += (xj + xi) * cross
+		cy += (yj + yi) * cross
+	}
+	if a == 0 {
+		return o
+	}
+	return [2]float64{o[0] + cx/(3*a), o[1] + cy/(3*a)}
+}
+
+func ringsBBox(rings [][][2]float64) [4]float64 {
+	bb := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, ring := range rings {
+		for _, p := range ring {
+			bb[0] = min(bb[0], p[0])
+			bb[1] = min(bb[1], p[1])
+			bb[2] = max(bb[2], p[0])
+			bb[3] = max(bb[3], p[1])
+		}
+	}
+	return bb
+}
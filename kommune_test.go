@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// square returns a clockwise ArcGIS ring with lower left corner (x, y).
+func square(x, y, size float64) [][2]float64 {
+	return [][2]float64{{x, y}, {x, y + size}, {x + size, y + size}, {x + size, y}, {x, y}}
+}
+
+// reversed returns ring in the opposite orientation.
+func reversed(ring [][2]float64) [][2]float64 {
+	r := make([][2]float64, len(ring))
+	for i, p := range ring {
+		r[len(ring)-1-i] = p
+	}
+	return r
+}
+
+func TestRingsContain(t *testing.T) {
+	withHole := [][][2]float64{square(0, 0, 10), reversed(square(4, 4, 2))}
+	tests := []struct {
+		name  string
+		rings [][][2]float64
+		x, y  float64
+		want  bool
+	}{
+		{"inside", withHole, 2, 2, true},
+		{"outside", withHole, 12, 2, false},
+		{"in hole", withHole, 5, 5, false},
+		{"hole with outer orientation", [][][2]float64{square(0, 0, 10), square(4, 4, 2)}, 5, 5, false},
+		{"between hole and edge", withHole, 7, 5, true},
+		{"left edge", withHole, 0, 5, true},
+		{"right edge", withHole, 10, 5, false},
+		{"bottom edge", withHole, 5, 0, true},
+		{"top edge", withHole, 5, 10, false},
+		{"vertex", withHole, 0, 0, true},
+		{"open ring", [][][2]float64{square(0, 0, 10)[:4]}, 5, 5, true},
+		{"no rings", nil, 5, 5, false},
+	}
+	for _, tt := range tests {
+		if got := ringsContain(tt.rings, tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: ringsContain(%v, %v) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// A point on the border between two municipalities belongs to exactly one.
+func TestRingsContainSharedEdge(t *testing.T) {
+	west := [][][2]float64{square(0, 0, 10)}
+	east := [][][2]float64{square(10, 0, 10)}
+	for _, y := range []float64{0, 5, 9.5} {
+		if ringsContain(west, 10, y) == ringsContain(east, 10, y) {
+			t.Errorf("point (10, %v) in both or neither polygon", y)
+		}
+	}
+}
+
+func TestShoelace(t *testing.T) {
+	tests := []struct {
+		name string
+		ring [][2]float64
+		want float64
+	}{
+		{"clockwise", square(0, 0, 10), 100},
+		{"counterclockwise", reversed(square(0, 0, 10)), -100},
+		{"open ring", square(0, 0, 10)[:4], 100},
+		{"triangle", [][2]float64{{0, 0}, {0, 4}, {3, 0}}, 6},
+		{"degenerate", [][2]float64{{0, 0}, {5, 5}, {10, 10}}, 0},
+		{"UTM 33N square kilometre", square(597000, 6643000, 1000), 1e6},
+	}
+	for _, tt := range tests {
+		if got := shoelace(tt.ring); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: shoelace = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRingsArea(t *testing.T) {
+	tests := []struct {
+		name  string
+		rings [][][2]float64
+		want  float64
+	}{
+		{"single ring", [][][2]float64{square(0, 0, 10)}, 100},
+		{"with hole", [][][2]float64{square(0, 0, 10), reversed(square(4, 4, 2))}, 96},
+		{"counterclockwise outer ring", [][][2]float64{reversed(square(0, 0, 10))}, 100},
+		{"UTM 33N with hole", [][][2]float64{square(597000, 6643000, 1000), reversed(square(597250, 6643250, 500))}, 750000},
+	}
+	for _, tt := range tests {
+		if got := ringsArea(tt.rings); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: ringsArea = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRingCentroid(t *testing.T) {
+	tests := []struct {
+		name string
+		ring [][2]float64
+		want [2]float64
+	}{
+		{"square", square(0, 0, 10), [2]float64{5, 5}},
+		{"counterclockwise", reversed(square(0, 0, 10)), [2]float64{5, 5}},
+		{"L shape", [][2]float64{{0, 0}, {0, 20}, {10, 20}, {10, 10}, {20, 10}, {20, 0}}, [2]float64{25.0 / 3, 25.0 / 3}},
+		{"UTM 33N", square(597000, 6643000, 1000), [2]float64{597500, 6643500}},
+		{"degenerate", [][2]float64{{3, 4}, {3, 4}, {3, 4}}, [2]float64{3, 4}},
+	}
+	for _, tt := range tests {
+		got := ringCentroid(tt.ring)
+		if math.Abs(got[0]-tt.want[0]) > 1e-6 || math.Abs(got[1]-tt.want[1]) > 1e-6 {
+			t.Errorf("%s: ringCentroid = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountAddressesInPolygons(t *testing.T) {
+	// Sorted by easting, as getKommuneAddresses returns them.
+	points := [][2]float64{
+		{1, 1},   // first polygon
+		{5, 5},   // in the first polygon's hole
+		{8, 8},   // in both polygons
+		{12, 12}, // second polygon
+		{30, 30}, // outside
+	}
+	polygons := [][][][2]float64{
+		{square(0, 0, 10), reversed(square(4, 4, 2))},
+		{square(7, 7, 10)},
+	}
+	if got := countAddressesInPolygons(points, polygons); got != 3 {
+		t.Errorf("got %d, want 3", got)
+	}
+	if got := countAddressesInPolygons(points, nil); got != 0 {
+		t.Errorf("no polygons: got %d, want 0", got)
+	}
+}
+
+func TestGetKommuneBoundary(t *testing.T) {
+	// Kommuneinfo returns GeoJSON in UTM 33N: an island with a lake and a
+	// separate islet, outer rings counterclockwise as GeoJSON requires.
+	const body = `{"kommunenavn": "Testøy", "omrade": {"type": "MultiPolygon", "coordinates": [
+		[[[597000, 6643000], [599000, 6643000], [599000, 6645000], [597000, 6645000], [597000, 6643000]],
+		 [[597500, 6643500], [597500, 6644500], [598500, 6644500], [598500, 6643500], [597500, 6643500]]],
+		[[[600000, 6643000], [600500, 6643000], [600500, 6643500], [600000, 6643500], [600000, 6643000]]]
+	]}}`
+	cache := NewCache()
+	cache.Set(kommuneInfoURL+"/9999/omrade?utkoordsys="+kommuneSR, []byte(body), time.Hour)
+
+	b, err := getKommuneBoundary(context.Background(), cache, "9999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.name != "Testøy" {
+		t.Errorf("name %q", b.name)
+	}
+	if want := 4e6 - 1e6 + 0.25e6; math.Abs(b.area-want) > 1e-6 {
+		t.Errorf("area %v m², want %v", b.area, want)
+	}
+	if want := [4]float64{597000, 6643000, 600500, 6645000}; b.bbox != want {
+		t.Errorf("bbox %v, want %v", b.bbox, want)
+	}
+	for _, p := range []struct {
+		x, y float64
+		want bool
+	}{
+		{597200, 6643200, true},  // on the island
+		{598000, 6644000, false}, // in the lake
+		{600250, 6643250, true},  // on the islet
+		{599500, 6644000, false}, // in the sound
+	} {
+		if got := b.contains(p.x, p.y); got != p.want {
+			t.Errorf("contains(%v, %v) = %v, want %v", p.x, p.y, got, p.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ var staticFiles embed.FS
 
 func main() {
 	port := flag.Int("port", 8080, "HTTP server port")
+	dataDir := flag.String("data-dir", "", "directory for stored assessments; storage is off when empty")
 	flag.Parse()
 
 	if p := os.Getenv("PORT"); p != "" {
@@ -25,6 +26,9 @@ func main() {
 		}
 		*port = v
 	}
+	if d := os.Getenv("DATA_DIR"); d != "" {
+		*dataDir = d
+	}
 
 	staticFS, err := fs.Sub(staticFiles, "static")
 	if err != nil {
@@ -33,7 +37,17 @@ func main() {
 
 	cache := NewCache()
 	defer cache.Close()
-	handler := newMux(staticFS, cache)
+
+	var store *Store
+	if *dataDir != "" {
+		store, err = OpenStore(*dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer store.Close()
+		log.Printf("Storing assessments in %s", *dataDir)
+	}
+	handler := newMux(staticFS, cache, store)
 
 	addr := fmt.Sprintf(":%d", *port)
 	log.Printf("Starting server on %s", addr)
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
-const metalertsURL = "https://api.met.no/weatherapi/metalerts/2.0/current.json"
+// all.json includes alerts that have been issued but not yet started, which
+// current.json leaves out.
+const metalertsURL = "https://api.met.no/weatherapi/metalerts/2.0/all.json"
 const metalertsCacheTTL = 5 * time.Minute
 
 type metalertsResponse struct {
@@ -15,20 +18,50 @@ type metalertsResponse struct {
 }
 
 type metalertsFeature struct {
-	Properties metalertsProps `json:"properties"`
+	Properties metalertsProps  `json:"properties"`
+	Geometry   json.RawMessage `json:"geometry"`
+	When       struct {
+		Interval []string `json:"interval"` // onset, expires
+	} `json:"when"`
 }
 
 type metalertsProps struct {
-	Event       string `json:"event"`
-	Severity    string `json:"severity"`
-	Description string `json:"description"`
-	Instruction string `json:"instruction"`
-	Area        string `json:"area"`
+	Event          string `json:"event"`
+	Severity       string `json:"severity"`
+	Description    string `json:"description"`
+	Instruction    string `json:"instruction"`
+	Area           string `json:"area"`
+	AwarenessLevel string `json:"awareness_level"` // e.g. "2; yellow; Moderate"
+	AwarenessType  string `json:"awareness_type"`  // e.g. "1; Wind"
+	Certainty      string `json:"certainty"`
+	Consequences   string `json:"consequences"`
 }
 
-// getWeatherAlerts fetches active weather warnings near a point.
-func getWeatherAlerts(ctx context.Context, cache *Cache, lat, lon float64) ([]WeatherAlert, error) {
-	u := fmt.Sprintf("%s?lat=%f&lon=%f", metalertsURL, lat, lon)
+// severityRank orders MetAlerts severities, most severe first. Unknown
+// severities rank after Minor; see alertSeverityRank.
+var severityRank = map[string]int{
+	"Extreme":  1,
+	"Severe":   2,
+	"Moderate": 3,
+	"Minor":    4,
+}
+
+func alertSeverityRank(severity string) int {
+	if r, ok := severityRank[severity]; ok {
+		return r
+	}
+	return len(severityRank) + 1
+}
+
+// getWeatherAlerts fetches active and upcoming weather warnings near a point,
+// most severe first and then by onset. Expired alerts are left out. MET
+// issues alerts in Norwegian and English; other languages get Norwegian.
+func getWeatherAlerts(ctx context.Context, cache *Cache, lat, lon float64, loc localizer) ([]WeatherAlert, error) {
+	lang := "no"
+	if loc.Lang == langEnglish {
+		lang = "en"
+	}
+	u := fmt.Sprintf("%s?lat=%f&lon=%f&lang=%s", metalertsURL, lat, lon, lang)
 
 	data, err := cachedGet(ctx, cache, u, metalertsCacheTTL)
 	if err != nil {
@@ -40,15 +73,57 @@ func getWeatherAlerts(ctx context.Context, cache *Cache, lat, lon float64) ([]We
 		return nil, fmt.Errorf("metalerts decode: %w", err)
 	}
 
+	now := time.Now()
 	alerts := make([]WeatherAlert, 0, len(result.Features))
 	for _, f := range result.Features {
-		alerts = append(alerts, WeatherAlert{
-			Event:       f.Properties.Event,
-			Severity:    f.Properties.Severity,
-			Description: f.Properties.Description,
-			Instruction: f.Properties.Instruction,
-			Area:        f.Properties.Area,
-		})
+		a := WeatherAlert{
+			Event:          f.Properties.Event,
+			Severity:       f.Properties.Severity,
+			Description:    f.Properties.Description,
+			Instruction:    f.Properties.Instruction,
+			Area:           f.Properties.Area,
+			AwarenessLevel: f.Properties.AwarenessLevel,
+			AwarenessType:  f.Properties.AwarenessType,
+			Certainty:      f.Properties.Certainty,
+			Consequences:   f.Properties.Consequences,
+		}
+		if len(f.Geometry) > 0 && string(f.Geometry) != "null" {
+			a.Geometry = f.Geometry
+		}
+		if len(f.When.Interval) == 2 {
+			a.Onset, a.Expires = f.When.Interval[0], f.When.Interval[1]
+		}
+		if expires, err := time.Parse(time.RFC3339, a.Expires); err == nil && !expires.After(now) {
+			continue
+		}
+		alerts = append(alerts, a)
 	}
+
+	// Onsets are compared as times, since MET may give them with different
+	// UTC offsets. Alerts without a valid onset go last.
+	sort.SliceStable(alerts, func(i, j int) bool {
+		ri, rj := alertSeverityRank(alerts[i].Severity), alertSeverityRank(alerts[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		oi, erri := time.Parse(time.RFC3339, alerts[i].Onset)
+		oj, errj := time.Parse(time.RFC3339, alerts[j].Onset)
+		if erri != nil || errj != nil {
+			return erri == nil && errj != nil
+		}
+		return oi.Before(oj)
+	})
 	return alerts, nil
 }
+
+// activeAt reports whether the alert is in effect at t. Alerts without a
+// valid interval are treated as active.
+func (a WeatherAlert) activeAt(t time.Time) bool {
+	if onset, err := time.Parse(time.RFC3339, a.Onset); err == nil && t.Before(onset) {
+		return false
+	}
+	if expires, err := time.Parse(time.RFC3339, a.Expires); err == nil && !t.Before(expires) {
+		return false
+	}
+	return true
+}
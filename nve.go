@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -34,10 +38,25 @@ var nveServices = []nveService{
 	{Name: "avalanche", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/SnoskredAktsomhet/MapServer", Layer: 1},
 	// Rock fall
 	{Name: "rock_fall", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/SkredSteinAktR/MapServer", Layer: 2},
-	// Combined hazard zones (100-year)
-	{Name: "combined_hazard", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Skredfaresoner2/MapServer", Layer: 2},
+	// Landslide hazard zones by annual probability (TEK17 S1, S2, S3)
+	{Name: "hazard_zone_100yr", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Skredfaresoner2/MapServer", Layer: 2},
+	{Name: "hazard_zone_1000yr", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Skredfaresoner2/MapServer", Layer: 3},
+	{Name: "hazard_zone_5000yr", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Skredfaresoner2/MapServer", Layer: 4},
+	// Mapping coverage (kartleggingsområder) for flood and landslide hazard zones
+	{Name: "flood_mapping_area", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Flomsoner1/MapServer", Layer: 0},
+	{Name: "hazard_zone_mapping_area", BaseURL: "https://nve.geodataonline.no/arcgis/rest/services/Skredfaresoner2/MapServer", Layer: 0},
 }
 
+// Coverage status of a hazard result: whether NVE has mapped the area in
+// detail, only covers it with national awareness maps, or not at all.
+// Estimates from terrain data are not NVE maps and have their own status.
+const (
+	coverageMapped    = "mapped"
+	coverageNotMapped = "not_mapped"
+	coverageAwareness = "awareness_only"
+	coverageEstimated = "estimated"
+)
+
 type arcgisResponse struct {
 	Features []arcgisFeature `json:"features"`
 }
@@ -67,3 +86,209 @@ func queryNVE(ctx context.Context, cache *Cache, svc nveService, lat, lon float6
 
 	return &result, nil
 }
+
+type arcgisIdentifyResponse struct {
+	Results []arcgisIdentifyResult `json:"results"`
+}
+
+type arcgisIdentifyResult struct {
+	LayerID    int            `json:"layerId"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// identifyNVE runs a single identify request against several layers of the
+// same MapServer and returns the intersecting features grouped by layer ID.
+// The point must fall inside a polygon (zero pixel tolerance).
+func identifyNVE(ctx context.Context, cache *Cache, baseURL string, layers []int, lat, lon float64) (map[int][]arcgisFeature, error) {
+	// identify needs a map extent and image size to resolve the tolerance;
+	// a tiny extent around the point keeps the request cheap.
+	const d = 0.001
+	geometry := fmt.Sprintf("%f,%f", lon, lat)
+	return identifyNVEGeometry(ctx, cache, baseURL, layers, "esriGeometryPoint", geometry, [4]float64{lon - d, lat - d, lon + d, lat + d})
+}
+
+// identifyNVEPolygon is identifyNVE for a polygon given as [lat, lon]
+// vertices: it returns the features of the layers that intersect it.
+func identifyNVEPolygon(ctx context.Context, cache *Cache, baseURL string, layers []int, ring [][2]float64) (map[int][]arcgisFeature, error) {
+	if len(ring) < 3 {
+		return nil, fmt.Errorf("nve identify: polygon has %d vertices", len(ring))
+	}
+
+	// Esri rings are closed, and clockwise for an exterior ring; a
+	// counterclockwise one would be read as a hole.
+	var area float64
+	rings := make([][2]float64, 0, len(ring)+1)
+	extent := [4]float64{ring[0][1], ring[0][0], ring[0][1], ring[0][0]}
+	for i, c := range ring {
+		next := ring[(i+1)%len(ring)]
+		area += c[1]*next[0] - next[1]*c[0]
+		rings = append(rings, [2]float64{c[1], c[0]})
+		extent = [4]float64{min(extent[0], c[1]), min(extent[1], c[0]), max(extent[2], c[1]), max(extent[3], c[0])}
+	}
+	if area > 0 {
+		slices.Reverse(rings)
+	}
+	if rings[0] != rings[len(rings)-1] {
+		rings = append(rings, rings[0])
+	}
+
+	geometry, err := json.Marshal(map[string]any{"rings": [][][2]float64{rings}})
+	if err != nil {
+		return nil, fmt.Errorf("nve identify: %w", err)
+	}
+	return identifyNVEGeometry(ctx, cache, baseURL, layers, "esriGeometryPolygon", string(geometry), extent)
+}
+
+// identifyNVEGeometry runs an identify request with any geometry. extent is
+// the map extent as xmin, ymin, xmax, ymax in WGS84.
+func identifyNVEGeometry(ctx context.Context, cache *Cache, baseURL string, layers []int, geometryType, geometry string, extent [4]float64) (map[int][]arcgisFeature, error) {
+	ids := make([]string, len(layers))
+	for i, l := range layers {
+		ids[i] = strconv.Itoa(l)
+	}
+
+	// By default identify keys attributes by field alias with
+	// display-formatted values, so ask for field names and raw values as
+	// query returns them.
+	u := fmt.Sprintf(
+		"%s/identify?geometry=%s&geometryType=%s&sr=4326&layers=all:%s&tolerance=0&mapExtent=%f,%f,%f,%f&imageDisplay=100,100,96&returnGeometry=false&returnFieldName=true&returnUnformattedValues=true&f=json",
+		baseURL, url.QueryEscape(geometry), geometryType, strings.Join(ids, ","), extent[0], extent[1], extent[2], extent[3],
+	)
+
+	data, err := cachedGet(ctx, cache, u, nveCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("nve identify: %w", err)
+	}
+
+	var result arcgisIdentifyResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("nve identify decode: %w", err)
+	}
+
+	byLayer := make(map[int][]arcgisFeature)
+	for _, r := range result.Results {
+		byLayer[r.LayerID] = append(byLayer[r.LayerID], arcgisFeature{Attributes: r.Attributes})
+	}
+	return byLayer, nil
+}
+
+// zoneAttributes holds the typed NVE feature attributes used to refine
+// scoring. Layers name their fields differently, so each is looked up under
+// several candidate keys.
+type zoneAttributes struct {
+	ZoneType     string   // zoneRelease or zoneRunout, empty if not given
+	FloodDepth   *float64 // meters
+	ReturnPeriod int      // years
+	MappedDate   string   // YYYY-MM-DD
+}
+
+const (
+	zoneRelease = "utløsningsområde"
+	zoneRunout  = "utløpsområde"
+)
+
+// parseZoneAttributes merges the attributes of all intersecting features,
+// keeping the most severe value of each: release over runout, the deepest
+// flood, the shortest return period and the most recent mapping date.
+func parseZoneAttributes(features []arcgisFeature) zoneAttributes {
+	var z zoneAttributes
+	for _, f := range features {
+		attrs := f.Attributes
+
+		switch t := strings.ToLower(attrString(attrs, "omrType", "omradeType", "omraadeType", "sonetype", "skredOmrType")); {
+		case strings.Contains(t, "utløsning"), strings.Contains(t, "utlosning"):
+			z.ZoneType = zoneRelease
+		case strings.Contains(t, "utløp"), strings.Contains(t, "utlop"):
+			if z.ZoneType == "" {
+				z.ZoneType = zoneRunout
+			}
+		}
+
+		if d, ok := attrFloat(attrs, "vanndybde", "dybde", "flomdybde", "maksDybde"); ok && d > 0 {
+			if z.FloodDepth == nil || d > *z.FloodDepth {
+				z.FloodDepth = &d
+			}
+		}
+
+		if rp := parseReturnPeriod(attrs); rp > 0 && (z.ReturnPeriod == 0 || rp < z.ReturnPeriod) {
+			z.ReturnPeriod = rp
+		}
+
+		if d := attrDate(attrs, "kartleggingsdato", "kartlagtDato", "datafangstdato"); d > z.MappedDate {
+			z.MappedDate = d
+		}
+	}
+	return z
+}
+
+// parseReturnPeriod reads a return period in years from attributes given as
+// a number, an annual probability ("1/1000") or a TEK17 safety class ("S2").
+func parseReturnPeriod(attrs map[string]any) int {
+	if v, ok := attrFloat(attrs, "gjentaksintervall", "returperiode"); ok && v > 0 {
+		return int(v)
+	}
+	s := strings.TrimSpace(attrString(attrs, "skredAarsannsynlighet", "aarsannsynlighet", "sikkerhetsklasse"))
+	if rest, ok := strings.CutPrefix(s, "1/"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil {
+			return n
+		}
+	}
+	switch strings.ToUpper(s) {
+	case "S1":
+		return 100
+	case "S2":
+		return 1000
+	case "S3":
+		return 5000
+	}
+	return 0
+}
+
+// refineScore adjusts a zone's base score using its attributes: release
+// areas and deep flooding score higher, rarer return periods lower.
+func (z zoneAttributes) refineScore(base int) int {
+	score := base
+	if z.ZoneType == zoneRelease {
+		score += 10
+	}
+	if z.FloodDepth != nil {
+		switch {
+		case *z.FloodDepth >= 1:
+			score += 10
+		case *z.FloodDepth < 0.5:
+			score -= 10
+		}
+	}
+	switch {
+	case z.ReturnPeriod >= 5000:
+		score -= 30
+	case z.ReturnPeriod >= 1000:
+		score -= 15
+	}
+	return max(1, min(100, score))
+}
+
+// apply copies the attributes onto a hazard result and extends its
+// description and details accordingly.
+func (z zoneAttributes) apply(h *HazardResult, loc localizer) {
+	h.ZoneType = z.ZoneType
+	h.FloodDepth = z.FloodDepth
+	h.ReturnPeriod = z.ReturnPeriod
+	h.MappedDate = z.MappedDate
+
+	if z.ZoneType != "" {
+		h.Description += " (" + loc.T(z.ZoneType) + ")"
+	}
+	if z.FloodDepth != nil {
+		h.Details += loc.Sprintf(" Beregnet vanndybde: %.1f m.", *z.FloodDepth)
+	}
+	if z.ReturnPeriod > 0 {
+		h.Details += loc.Sprintf(" Nominell årlig sannsynlighet: 1/%d.", z.ReturnPeriod)
+	}
+	if z.MappedDate != "" {
+		h.Details += loc.Sprintf(" Kartlagt %s.", z.MappedDate[:4])
+		if t, err := time.Parse("2006-01-02", z.MappedDate); err == nil && time.Since(t) > 20*365*24*time.Hour {
+			h.Details += loc.T(" Kartleggingen er over 20 år gammel og kan være utdatert.")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestParseReturnPeriod(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]any
+		want  int
+	}{
+		{"number", map[string]any{"gjentaksintervall": 200.0}, 200},
+		{"numeric string", map[string]any{"returperiode": "1000"}, 1000},
+		{"annual probability", map[string]any{"skredAarsannsynlighet": "1/5000"}, 5000},
+		{"annual probability with spaces", map[string]any{"aarsannsynlighet": " 1/ 100 "}, 100},
+		{"safety class", map[string]any{"sikkerhetsklasse": "s2"}, 1000},
+		{"missing", map[string]any{}, 0},
+		{"null", map[string]any{"gjentaksintervall": nil, "sikkerhetsklasse": nil}, 0},
+		{"zero falls through", map[string]any{"gjentaksintervall": 0.0, "sikkerhetsklasse": "S3"}, 5000},
+		{"malformed probability", map[string]any{"skredAarsannsynlighet": "1/mange"}, 0},
+		{"unknown class", map[string]any{"sikkerhetsklasse": "S9"}, 0},
+		{"wrong type", map[string]any{"gjentaksintervall": true}, 0},
+	}
+	for _, tt := range tests {
+		if got := parseReturnPeriod(tt.attrs); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestZoneAttributesRefineScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []map[string]any
+		base     int
+
+		zoneType string
+		want     int
+	}{
+		{"no attributes", []map[string]any{{}}, 60, "", 60},
+		{"no features", nil, 60, "", 60},
+		{"release area", []map[string]any{{"omrType": "Utløsningsområde"}}, 60, zoneRelease, 70},
+		{"runout area, ascii", []map[string]any{{"sonetype": "utlopsomrade"}}, 60, zoneRunout, 60},
+		{"release wins over runout", []map[string]any{{"omrType": "utløpsområde"}, {"omrType": "utløsningsområde"}}, 60, zoneRelease, 70},
+		{"deep flood", []map[string]any{{"vanndybde": 1.2}}, 40, "", 50},
+		{"shallow flood", []map[string]any{{"flomdybde": "0,3"}}, 40, "", 30},
+		{"deepest flood wins", []map[string]any{{"vanndybde": 0.2}, {"dybde": 1.0}}, 40, "", 50},
+		{"malformed depth", []map[string]any{{"vanndybde": "dypt"}}, 40, "", 40},
+		{"negative depth", []map[string]any{{"vanndybde": -1.0}}, 40, "", 40},
+		{"1000-year zone", []map[string]any{{"sikkerhetsklasse": "S2"}}, 80, "", 65},
+		{"shortest return period wins", []map[string]any{{"sikkerhetsklasse": "S3"}, {"skredAarsannsynlighet": "1/100"}}, 80, "", 80},
+		{"5000-year zone", []map[string]any{{"skredAarsannsynlighet": "1/5000"}}, 80, "", 50},
+		{"clamped low", []map[string]any{{"flomdybde": 0.1, "gjentaksintervall": 5000.0}}, 20, "", 1},
+		{"clamped high", []map[string]any{{"omrType": "utløsningsområde", "vanndybde": 2.0}}, 95, zoneRelease, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var features []arcgisFeature
+			for _, a := range tt.features {
+				features = append(features, arcgisFeature{Attributes: a})
+			}
+			z := parseZoneAttributes(features)
+			if z.ZoneType != tt.zoneType {
+				t.Errorf("zone type %q, want %q", z.ZoneType, tt.zoneType)
+			}
+			if got := z.refineScore(tt.base); got != tt.want {
+				t.Errorf("refineScore(%d) = %d, want %d", tt.base, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseZoneAttributesMappedDate(t *testing.T) {
+	z := parseZoneAttributes([]arcgisFeature{
+		{Attributes: map[string]any{"kartleggingsdato": "2004-03-01"}},
+		{Attributes: map[string]any{"kartlagtDato": "2019-11-20T00:00:00Z"}},
+		{Attributes: map[string]any{"datafangstdato": "ukjent"}},
+		{Attributes: map[string]any{"kartleggingsdato": nil}},
+	})
+	if z.MappedDate != "2019-11-20" {
+		t.Errorf("mapped date %q, want 2019-11-20", z.MappedDate)
+	}
+}
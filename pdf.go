@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// A minimal PDF writer for the risk report: A4 pages, the Helvetica base
+// fonts, filled rectangles and RGB images. Coordinates are in points from
+// the top left corner of the page.
+
+const (
+	pdfPageWidth  = 595.0 // A4
+	pdfPageHeight = 842.0
+)
+
+type pdfDoc struct {
+	pages  []*bytes.Buffer // content streams
+	images []pdfImage
+}
+
+type pdfImage struct {
+	width, height int
+	data          []byte // zlib-compressed RGB
+}
+
+func newPDF() *pdfDoc {
+	return &pdfDoc{}
+}
+
+// AddPage starts a new page; drawing goes to the last page.
+func (d *pdfDoc) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *pdfDoc) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline at y.
+func (d *pdfDoc) Text(x, y, size float64, bold bool, c color.Color, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %s rg %.2f %.2f Td (%s) Tj ET\n",
+		font, size, pdfColor(c), x, pdfPageHeight-y, pdfEscape(winAnsi(s)))
+}
+
+// Rect fills a rectangle with its top left corner at x, y.
+func (d *pdfDoc) Rect(x, y, w, h float64, c color.Color) {
+	fmt.Fprintf(d.page(), "%s rg %.2f %.2f %.2f %.2f re f\n", pdfColor(c), x, pdfPageHeight-y-h, w, h)
+}
+
+// Line draws a straight line.
+func (d *pdfDoc) Line(x1, y1, x2, y2, width float64, c color.Color) {
+	fmt.Fprintf(d.page(), "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		pdfColor(c), width, x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// Image draws img scaled into the rectangle with its top left corner at x, y.
+func (d *pdfDoc) Image(img image.Image, x, y, w, h float64) {
+	b := img.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			c := color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+		}
+	}
+	d.images = append(d.images, pdfImage{width: b.Dx(), height: b.Dy(), data: deflate(rgb)})
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, pdfPageHeight-y-h, len(d.images))
+}
+
+// Bytes returns the finished document.
+func (d *pdfDoc) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3-4 fonts, then the images, then a
+	// page and its content stream for each page.
+	var objects []string
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+2*i))
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+
+	var xobjects []string
+	for i, img := range d.images {
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, firstImage+i))
+		objects = append(objects, pdfStream(fmt.Sprintf(
+			"/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.data))
+	}
+	resources := "<< /Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(xobjects) > 0 {
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+	resources += " >>"
+
+	for i, content := range d.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, resources, firstPage+2*i+1),
+			pdfStream("/Filter /FlateDecode", deflate(content.Bytes())),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func pdfColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)
+}
+
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`).Replace(s)
+}
+
+// winAnsiExtra maps the characters outside Latin-1 that WinAnsiEncoding
+// has, and approximates the Sami letters it lacks. These are for the data,
+// not the interface language: address, municipality and event place names
+// in Sápmi (Kárášjohka, Čáhcesuolu) are written in Sami whatever language
+// the report is in.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, 'Š': 0x8a, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'™': 0x99, 'š': 0x9a, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+	'Č': 'C', 'č': 'c', 'Đ': 0xd0, 'đ': 'd', 'Ŋ': 'N', 'ŋ': 'n', 'Ŧ': 'T', 'ŧ': 't',
+	'≤': '<', '≥': '>', '−': '-',
+}
+
+// winAnsi converts s to WinAnsiEncoding, replacing what it cannot encode
+// with '?'.
+func winAnsi(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case r == '\t' || r == '\n':
+			out = append(out, ' ')
+		default:
+			if b, ok := winAnsiExtra[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return string(out)
+}
+
+// Glyph widths per 1000 units of font size for printable ASCII, from the
+// Adobe font metrics of Helvetica and Helvetica-Bold.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// Widths of the non-ASCII glyphs the reports use; others count as 556.
+var helveticaExtraWidths = map[byte]int{
+	0x85: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0x95: 350, 0x96: 556, 0x97: 1000,
+	0xb0: 400, 0xb1: 584, 0xb2: 333, 0xc5: 667, 0xc6: 1000, 0xd7: 584, 0xd8: 778, 0xe6: 889,
+}
+
+var helveticaBoldExtraWidths = map[byte]int{
+	0x85: 1000, 0x91: 278, 0x92: 278, 0x93: 500, 0x94: 500, 0x95: 350, 0x96: 556, 0x97: 1000,
+	0xb0: 400, 0xb1: 584, 0xb2: 333, 0xc5: 722, 0xc6: 1000, 0xd7: 584, 0xd8: 778, 0xe6: 889,
+}
+
+// textWidth returns the width of s in points.
+func textWidth(s string, size float64, bold bool) float64 {
+	widths, extra := &helveticaWidths, helveticaExtraWidths
+	if bold {
+		widths, extra = &helveticaBoldWidths, helveticaBoldExtraWidths
+	}
+	units := 0
+	for _, b := range []byte(winAnsi(s)) {
+		switch {
+		case b >= 0x20 && b < 0x7f:
+			units += widths[b-0x20]
+		case extra[b] != 0:
+			units += extra[b]
+		default:
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// wrapText breaks s into lines no wider than width.
+func wrapText(s string, size, width float64, bold bool) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && textWidth(candidate, size, bold) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// previewTTL is how long the score of an assessment stays available for
+// the preview tags of its permalink.
+const previewTTL = 1 * time.Hour
+
+// ogLocales are the Open Graph locales of the response languages.
+var ogLocales = map[string]string{
+	langBokmal:  "nb_NO",
+	langNynorsk: "nn_NO",
+	langEnglish: "en_GB",
+}
+
+var ogTemplate = template.Must(template.New("og").Parse(`
+  <meta property="og:type" content="website">
+  <meta property="og:site_name" content="{{.Site}}">
+  <meta property="og:title" content="{{.Title}}">
+  <meta property="og:description" content="{{.Description}}">
+  <meta property="og:url" content="{{.URL}}">
+  <meta property="og:locale" content="{{.Locale}}">
+  <meta name="twitter:card" content="summary">
+  <meta name="description" content="{{.Description}}">
+  <link rel="canonical" href="{{.URL}}">
+`))
+
+// handleIndex serves the static frontend. A permalink, the front page with
+// the parameters of /api/risk, gets Open Graph tags with the address and
+// the cached score so that shared links have a preview; the frontend itself
+// reads the parameters and runs the assessment.
+func handleIndex(staticFS fs.FS, cache *Cache) http.Handler {
+	files := http.FileServerFS(staticFS)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" || !r.URL.Query().Has("lat") {
+			files.ServeHTTP(w, r)
+			return
+		}
+		// An invalid permalink gets the plain page, which reports the error.
+		req, msg := parseRiskRequest(r)
+		if msg != "" {
+			files.ServeHTTP(w, r)
+			return
+		}
+
+		index, err := fs.ReadFile(staticFS, "index.html")
+		if err != nil {
+			log.Printf("index error: %v", err)
+			writeError(w, http.StatusInternalServerError, codeInternal, "internal server error")
+			return
+		}
+
+		var tags bytes.Buffer
+		if err := ogTemplate.Execute(&tags, permalinkPreview(r, cache, req)); err != nil {
+			log.Printf("permalink template error: %v", err)
+		}
+		page := bytes.Replace(index, []byte("</head>"), append(tags.Bytes(), "</head>"...), 1)
+
+		setLanguageHeaders(w, req.Loc)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+}
+
+type ogPreview struct {
+	Site, Title, Description, URL, Locale string
+}
+
+// riskPreview is the part of an assessment shown in a permalink's preview.
+type riskPreview struct {
+	Score int    `json:"score"`
+	Level string `json:"level"`
+}
+
+// previewKey identifies an assessment's preview in the cache. The overall
+// score depends on neither the scenario, the building option nor the
+// language; the summary is kept per language under summaryKey.
+func previewKey(addr Address) string {
+	return fmt.Sprintf("preview:%.6f,%.6f:%s", addr.Latitude, addr.Longitude, addr.Kommunenummer)
+}
+
+func summaryKey(addr Address, lang string) string {
+	return previewKey(addr) + ":" + lang
+}
+
+// cachePreview keeps an assessment's score for its permalink's preview. An
+// incomplete assessment is not kept, since a missing hazard would
+// understate the score.
+func cachePreview(cache *Cache, req riskRequest, hazards []HazardResult, score int, level, summary string) {
+	for _, h := range hazards {
+		if h.Error != "" {
+			return
+		}
+	}
+	data, err := json.Marshal(riskPreview{Score: score, Level: level})
+	if err != nil {
+		return
+	}
+	cache.Set(previewKey(req.Address), data, previewTTL)
+	cache.Set(summaryKey(req.Address, req.Loc.Lang), []byte(summary), previewTTL)
+}
+
+// permalinkPreview builds a permalink's preview tags. It never calls the
+// upstream APIs: the score is shown only when someone assessed the address
+// recently, which is the case for a link that was just shared.
+func permalinkPreview(r *http.Request, cache *Cache, req riskRequest) ogPreview {
+	loc := req.Loc
+	p := ogPreview{
+		Site:        loc.T("Hvor trygt bor du?"),
+		Title:       req.Address.Text,
+		Description: loc.T("Sjekk naturfare for din adresse"),
+		URL:         permalinkURL(r, req),
+		Locale:      ogLocales[loc.Lang],
+	}
+	if p.Title == "" {
+		p.Title = fmt.Sprintf("%.5f, %.5f", req.Address.Latitude, req.Address.Longitude)
+	}
+
+	data, ok := cache.Get(previewKey(req.Address))
+	if !ok {
+		return p
+	}
+	var preview riskPreview
+	if err := json.Unmarshal(data, &preview); err != nil {
+		return p
+	}
+	p.Title = loc.Sprintf("%s: %s (%d av 100)", p.Title, loc.T(overallLevelLabels[preview.Level]), preview.Score)
+	if summary, ok := cache.Get(summaryKey(req.Address, loc.Lang)); ok {
+		p.Description = string(summary)
+	}
+	return p
+}
+
+// permalinkURL returns the canonical permalink of an assessment. It leaves
+// out the language so that each reader gets their own.
+func permalinkURL(r *http.Request, req riskRequest) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return (&url.URL{Scheme: scheme, Host: r.Host, Path: "/", RawQuery: permalinkQuery(req).Encode()}).String()
+}
+
+// permalinkQuery holds the parameters that identify an assessment.
+func permalinkQuery(req riskRequest) url.Values {
+	q := url.Values{
+		"lat": {fmt.Sprint(req.Address.Latitude)},
+		"lon": {fmt.Sprint(req.Address.Longitude)},
+		"knr": {req.Address.Kommunenummer},
+	}
+	if req.Address.Text != "" {
+		q.Set("text", req.Address.Text)
+	}
+	if req.Address.Kommunenavn != "" {
+		q.Set("kommune", req.Address.Kommunenavn)
+	}
+	if req.Scenario != nil {
+		q.Set("scenario", req.Scenario.Name)
+	}
+	if req.Buildings {
+		q.Set("buildings", "true")
+	}
+	return q
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Layout of the PDF report, in points.
+const (
+	reportMargin = 50.0
+	reportWidth  = pdfPageWidth - 2*reportMargin
+
+	reportMaxEvents = 40 // rows in the historical events table
+)
+
+var (
+	reportText  = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	reportMuted = color.RGBA{0x6b, 0x6b, 0x6b, 0xff}
+	reportRule  = color.RGBA{0xdd, 0xdd, 0xdd, 0xff}
+)
+
+// levelColors match the level colors in the frontend's stylesheet.
+var levelColors = map[string]color.RGBA{
+	"low":       {0x2d, 0x8a, 0x4e, 0xff},
+	"medium":    {0xc0, 0x9a, 0x2b, 0xff},
+	"high":      {0xd9, 0x68, 0x30, 0xff},
+	"very_high": {0xc0, 0x39, 0x2b, 0xff},
+	"unknown":   {0x7f, 0x8c, 0x8d, 0xff},
+}
+
+var overallLevelLabels = map[string]string{
+	"low":       "Lav risiko",
+	"medium":    "Moderat risiko",
+	"high":      "Høy risiko",
+	"very_high": "Svært høy risiko",
+}
+
+var levelLabels = map[string]string{
+	"low":       "Lav",
+	"medium":    "Moderat",
+	"high":      "Høy",
+	"very_high": "Svært høy",
+	"unknown":   "Ukjent",
+}
+
+var coverageLabels = map[string]string{
+	"not_mapped":     "Ikke kartlagt",
+	"awareness_only": "Aktsomhetskart",
+	"estimated":      "Estimat",
+}
+
+var varsomTypeLabels = map[string]string{
+	"flood":     "Flomvarsel",
+	"landslide": "Jordskredvarsel",
+	"avalanche": "Snøskredvarsel",
+}
+
+// reportWriter lays out the report top to bottom, breaking pages as needed.
+type reportWriter struct {
+	pdf  *pdfDoc
+	loc  localizer
+	y    float64
+	page int
+}
+
+// renderReport renders an assessment as a PDF. mapImg may be nil when the
+// map could not be fetched.
+func renderReport(resp RiskResponse, mapImg image.Image, loc localizer) []byte {
+	w := &reportWriter{pdf: newPDF(), loc: loc}
+	w.newPage()
+
+	w.header(resp)
+	w.scoreBox(resp)
+	w.facts(resp)
+	w.hazardMap(mapImg)
+	w.hazards(resp.Hazards)
+	w.events(resp)
+	w.warnings(resp.WeatherAlerts, resp.VarsomWarnings)
+	w.sources(resp.Sources)
+	w.disclaimer()
+
+	return w.pdf.Bytes()
+}
+
+func (w *reportWriter) newPage() {
+	w.pdf.AddPage()
+	w.page++
+	w.y = reportMargin
+	footer := w.loc.Sprintf("Side %d", w.page)
+	w.pdf.Text(pdfPageWidth-reportMargin-textWidth(footer, 8, false), pdfPageHeight-25, 8, false, reportMuted, footer)
+	w.pdf.Text(reportMargin, pdfPageHeight-25, 8, false, reportMuted, w.loc.T("Hvor trygt bor du?"))
+}
+
+// need starts a new page unless h points fit on the current one.
+func (w *reportWriter) need(h float64) {
+	if w.y+h > pdfPageHeight-reportMargin {
+		w.newPage()
+	}
+}
+
+// paragraph writes wrapped text starting at x.
+func (w *reportWriter) paragraph(x float64, s string, size float64, bold bool, c color.Color) {
+	lineHeight := size * 1.35
+	for _, line := range wrapText(s, size, reportWidth-(x-reportMargin), bold) {
+		w.need(lineHeight)
+		w.y += lineHeight
+		w.pdf.Text(x, w.y-size*0.3, size, bold, c, line)
+	}
+}
+
+func (w *reportWriter) section(title string) {
+	w.need(90) // keep the heading with the start of the section
+	w.y += 18
+	w.paragraph(reportMargin, w.loc.T(title), 14, true, reportText)
+	w.y += 3
+	w.pdf.Line(reportMargin, w.y, reportMargin+reportWidth, w.y, 0.5, reportRule)
+	w.y += 6
+}
+
+func (w *reportWriter) header(resp RiskResponse) {
+	w.paragraph(reportMargin, w.loc.T("Risikorapport"), 22, true, reportText)
+	w.y += 4
+	addr := resp.Address.Text
+	if addr == "" {
+		addr = fmt.Sprintf("%.5f, %.5f", resp.Address.Latitude, resp.Address.Longitude)
+	}
+	w.paragraph(reportMargin, addr, 13, true, reportText)
+
+	kommune := resp.Address.Kommunenummer
+	if resp.Address.Kommunenavn != "" {
+		kommune = resp.Address.Kommunenavn + " (" + kommune + ")"
+	}
+	w.paragraph(reportMargin, w.loc.Sprintf("Kommune: %s · Koordinater: %.5f, %.5f", kommune, resp.Address.Latitude, resp.Address.Longitude), 9, false, reportMuted)
+	w.paragraph(reportMargin, w.loc.Sprintf("Generert %s", reportTime(resp.GeneratedAt)), 9, false, reportMuted)
+	if resp.AssessmentID != "" {
+		w.paragraph(reportMargin, w.loc.Sprintf("Vurderings-ID: %s", resp.AssessmentID), 9, false, reportMuted)
+	}
+	w.y += 10
+}
+
+func (w *reportWriter) scoreBox(resp RiskResponse) {
+	const h = 56
+	w.need(h)
+	w.pdf.Rect(reportMargin, w.y, reportWidth, h, levelColor(resp.OverallLevel))
+	w.pdf.Text(reportMargin+16, w.y+39, 30, true, color.White, fmt.Sprint(resp.OverallScore))
+	w.pdf.Text(reportMargin+80, w.y+25, 15, true, color.White, w.loc.T(overallLevelLabels[resp.OverallLevel]))
+	w.pdf.Text(reportMargin+80, w.y+42, 9, false, color.White, w.loc.T("Samlet score 0–100"))
+	w.y += h + 6
+	w.paragraph(reportMargin, resp.Summary, 10, false, reportText)
+}
+
+func (w *reportWriter) facts(resp RiskResponse) {
+	var lines []string
+	if resp.Elevation != nil {
+		text := w.loc.Sprintf("Høyde: %.1f moh.", *resp.Elevation)
+		if src := resp.ElevationSource; src != nil {
+			text += fmt.Sprintf(" ±%.1f m, %s", src.UncertaintyM, src.Datum)
+			if src.Source != "" {
+				text += ", " + strings.ToUpper(src.Source)
+			}
+		}
+		lines = append(lines, text)
+	}
+	if t := resp.Terrain; t != nil {
+		text := w.loc.Sprintf("Helning %.0f°", t.SlopeDeg)
+		if t.Aspect != "" {
+			text = w.loc.Sprintf("Helning %.0f° mot %s", t.SlopeDeg, t.Aspect)
+		}
+		if t.MaxRiseM > 0 {
+			text += ", " + w.loc.Sprintf("terrenget stiger %.0f m innen 100 m", t.MaxRiseM)
+		}
+		lines = append(lines, text)
+	}
+	if cr := resp.CurrentRisk; cr != nil && len(cr.Escalations) > 0 {
+		lines = append(lines, w.loc.Sprintf("Risiko nå: %d (%s) — %s", cr.Score, w.levelText(cr.Level), cr.Summary))
+	}
+	if p := resp.Projection; p != nil {
+		lines = append(lines, w.loc.Sprintf("Klimascenario %s: %d (%s)", p.Scenario, p.OverallScore, w.levelText(p.OverallLevel)))
+		if p.Note != "" {
+			lines = append(lines, p.Note)
+		}
+	}
+	for _, b := range resp.Buildings {
+		text := w.loc.Sprintf("Bygningen: verste punkt %d (%s)", b.OverallScore, w.levelText(b.OverallLevel))
+		if b.ID != "" {
+			text = w.loc.Sprintf("Bygning %s: verste punkt %d (%s)", b.ID, b.OverallScore, w.levelText(b.OverallLevel))
+		}
+		if b.LowestElevation != nil {
+			text += ", " + w.loc.Sprintf("laveste hjørne %.1f moh.", *b.LowestElevation)
+		}
+		lines = append(lines, text)
+	}
+	if resp.BuildingsError != "" {
+		lines = append(lines, resp.BuildingsError)
+	}
+
+	if len(lines) > 0 {
+		w.y += 4
+	}
+	for _, line := range lines {
+		w.paragraph(reportMargin, line, 9, false, reportText)
+	}
+}
+
+func (w *reportWriter) hazardMap(img image.Image) {
+	w.section("Farekart")
+	if img == nil {
+		w.paragraph(reportMargin, w.loc.T("Kartet kunne ikke hentes."), 9, false, reportMuted)
+		return
+	}
+	b := img.Bounds()
+	h := reportWidth * float64(b.Dy()) / float64(b.Dx())
+	w.need(h + 30)
+	w.pdf.Image(img, reportMargin, w.y, reportWidth, h)
+	w.y += h + 2
+	w.paragraph(reportMargin, w.loc.Sprintf("Kartgrunnlag: Kartverket. Faresoner og aktsomhetsområder: NVE. Adressen er markert med rød prikk. Kartet viser omtrent %d m til hver side.", mapRadiusM), 8, false, reportMuted)
+}
+
+func (w *reportWriter) hazards(hazards []HazardResult) {
+	w.section("Farer")
+
+	// Highest score first, errors last, as on the website.
+	sorted := append([]HazardResult(nil), hazards...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if (sorted[i].Error == "") != (sorted[j].Error == "") {
+			return sorted[i].Error == ""
+		}
+		return sorted[i].Score > sorted[j].Score
+	})
+
+	for _, h := range sorted {
+		w.need(40)
+		top, page := w.y+4, w.page
+
+		// A zero score where NVE has not mapped the area means unknown.
+		level := h.Level
+		if h.Coverage == coverageNotMapped && h.Score == 0 {
+			level = "unknown"
+		}
+		name := h.Name
+		if label, ok := coverageLabels[h.Coverage]; ok {
+			name += " (" + w.loc.T(label) + ")"
+		}
+
+		w.y += 4
+		const indent = reportMargin + 10
+		if h.Error != "" {
+			w.paragraph(indent, name, 10, true, reportText)
+			w.paragraph(indent, h.Error, 9, false, reportMuted)
+		} else {
+			score := fmt.Sprint(h.Score)
+			if level == "unknown" {
+				score = "?"
+			}
+			score += " · " + w.levelText(level)
+			w.need(14)
+			w.pdf.Text(reportMargin+reportWidth-textWidth(score, 10, true), w.y+10, 10, true, levelColor(level), score)
+			w.paragraph(indent, name, 10, true, reportText)
+
+			details := h.Details
+			if details == "" {
+				details = h.Description
+			}
+			w.paragraph(indent, details, 9, false, reportText)
+			for _, s := range h.StormSurgeScenarios {
+				w.paragraph(indent, w.loc.Sprintf("Scenario %s (%s): %d bygninger", s.Code, s.Year, s.Buildings), 8, false, reportMuted)
+			}
+			if p := h.Projected; p != nil {
+				text := p.Details
+				if text == "" {
+					text = p.Description
+				}
+				w.paragraph(indent, fmt.Sprintf("%s: %d · %s", p.Scenario, p.Score, text), 8, false, reportMuted)
+			}
+		}
+
+		// The level bar is drawn last, once the entry's height is known; an
+		// entry split across pages gets no bar.
+		if w.page == page {
+			w.pdf.Rect(reportMargin, top, 4, w.y-top, levelColor(level))
+		}
+		w.y += 4
+	}
+}
+
+func (w *reportWriter) events(resp RiskResponse) {
+	w.section("Historiske hendelser")
+	if len(resp.HistoricalEvents) == 0 {
+		w.paragraph(reportMargin, w.loc.T("Ingen registrerte hendelser i nærheten."), 9, false, reportMuted)
+		return
+	}
+	if s := resp.HistoricalSummary; s != nil {
+		w.paragraph(reportMargin, w.loc.Sprintf("%d hendelser, %d med bygningsskade, %d omkommet.", s.TotalEvents, s.BuildingDamage, s.Fatalities), 9, false, reportText)
+		w.y += 4
+	}
+
+	columns := []float64{70, 190, 60, 175} // date, type, distance, consequences
+	header := []string{w.loc.T("Dato"), w.loc.T("Type"), w.loc.T("Avstand"), w.loc.T("Skade")}
+	w.tableRow(columns, header, true)
+
+	shown := resp.HistoricalEvents
+	if len(shown) > reportMaxEvents {
+		shown = shown[:reportMaxEvents]
+	}
+	for _, e := range shown {
+		date := e.Date
+		if date == "" {
+			date = w.loc.T("ukjent dato")
+		}
+		var tags []string
+		if e.BuildingDamage {
+			tags = append(tags, w.loc.T("bygning"))
+		}
+		if e.RoadDamage {
+			tags = append(tags, w.loc.T("veg"))
+		}
+		if e.Fatalities > 0 {
+			tags = append(tags, w.loc.Sprintf("%d omkommet", e.Fatalities))
+		}
+		w.tableRow(columns, []string{date, w.loc.T(e.Type), fmt.Sprintf("%d m", e.DistanceMeters), strings.Join(tags, ", ")}, false)
+	}
+	if n := len(resp.HistoricalEvents) - len(shown); n > 0 {
+		w.paragraph(reportMargin, w.loc.Sprintf("+ %d flere hendelser", n), 8, false, reportMuted)
+	}
+	if resp.HistoricalEventsTruncated {
+		w.paragraph(reportMargin, w.loc.T("Søket nådde grensen for antall hendelser; listen kan være ufullstendig."), 8, false, reportMuted)
+	}
+}
+
+// tableRow writes one row of a table, wrapping cells within their column.
+func (w *reportWriter) tableRow(columns []float64, cells []string, bold bool) {
+	const size, lineHeight = 8.0, 11.0
+	wrapped := make([][]string, len(cells))
+	rows := 1
+	for i, cell := range cells {
+		wrapped[i] = wrapText(cell, size, columns[i]-6, bold)
+		rows = max(rows, len(wrapped[i]))
+	}
+	w.need(float64(rows)*lineHeight + 3)
+	x := reportMargin
+	for i, lines := range wrapped {
+		for j, line := range lines {
+			w.pdf.Text(x, w.y+float64(j+1)*lineHeight-3, size, bold, reportText, line)
+		}
+		x += columns[i]
+	}
+	w.y += float64(rows)*lineHeight + 3
+	w.pdf.Line(reportMargin, w.y-1, reportMargin+reportWidth, w.y-1, 0.3, reportRule)
+}
+
+func (w *reportWriter) warnings(alerts []WeatherAlert, varsom []VarsomWarning) {
+	w.section("Farevarsler")
+
+	// Varsom warnings from yellow up, as on the website.
+	var active []VarsomWarning
+	for _, v := range varsom {
+		if (v.Type == "avalanche" && v.Level >= 3) || (v.Type != "avalanche" && v.Level >= 2) {
+			active = append(active, v)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool { return active[i].ValidFrom < active[j].ValidFrom })
+
+	if len(alerts) == 0 && len(active) == 0 {
+		w.paragraph(reportMargin, w.loc.T("Ingen aktive farevarsler."), 9, false, reportMuted)
+		return
+	}
+	for _, a := range alerts {
+		title := a.Event + " — " + a.Severity
+		if a.Onset != "" {
+			title += " · " + reportTime(a.Onset) + " – " + reportTime(a.Expires)
+		}
+		w.need(30)
+		w.paragraph(reportMargin, title, 9, true, reportText)
+		w.paragraph(reportMargin, a.Description, 9, false, reportText)
+		if a.Consequences != "" {
+			w.paragraph(reportMargin, a.Consequences, 9, false, reportText)
+		}
+		w.y += 4
+	}
+	for _, v := range active {
+		title := w.loc.T(varsomTypeLabels[v.Type]) + " — " + w.loc.Sprintf("nivå %d", v.Level)
+		if v.Region != "" {
+			title += " (" + v.Region + ")"
+		}
+		if len(v.ValidFrom) >= 10 {
+			title += " · " + v.ValidFrom[:10]
+		}
+		w.need(30)
+		w.paragraph(reportMargin, title, 9, true, reportText)
+		w.paragraph(reportMargin, v.MainText, 9, false, reportText)
+		w.y += 4
+	}
+}
+
+func (w *reportWriter) sources(sources []DataSource) {
+	w.section("Datakilder")
+	w.paragraph(reportMargin, w.loc.T("Tidspunktene er når dataene ble hentet fra kilden. Svar fra hurtigbufferen har tidspunktet fra den opprinnelige hentingen."), 8, false, reportMuted)
+	w.y += 4
+	for _, s := range sources {
+		w.need(24)
+		retrieved := reportTime(s.RetrievedAt)
+		w.pdf.Text(reportMargin+reportWidth-textWidth(retrieved, 8, false), w.y+10, 8, false, reportText, retrieved)
+		w.paragraph(reportMargin, s.Name, 9, true, reportText)
+		w.paragraph(reportMargin, s.URL, 7, false, reportMuted)
+		w.y += 3
+	}
+}
+
+func (w *reportWriter) disclaimer() {
+	w.section("Forbehold")
+	w.paragraph(reportMargin, w.loc.T("Kun veiledende — erstatter ikke profesjonell vurdering."), 9, true, reportText)
+	w.paragraph(reportMargin, w.loc.T("Rapporten bygger på åpne data fra NVE, Kartverket og MET slik de var på tidspunktene over. NVE-kartene dekker ikke hele landet; at en adresse ligger utenfor kartlagte soner betyr ikke nødvendigvis at det ikke er fare der."), 9, false, reportText)
+}
+
+func (w *reportWriter) levelText(level string) string {
+	if label, ok := levelLabels[level]; ok {
+		return w.loc.T(label)
+	}
+	return level
+}
+
+func levelColor(level string) color.RGBA {
+	if c, ok := levelColors[level]; ok {
+		return c
+	}
+	return levelColors["unknown"]
+}
+
+// reportTime formats an RFC 3339 timestamp in UTC.
+func reportTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
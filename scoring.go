@@ -1,9 +1,19 @@
 package main
 
-import "fmt"
+import "strings"
 
-// calculateRisk computes the overall risk score and Norwegian summary.
-func calculateRisk(hazards []HazardResult, elevation *float64, kommunenummer string) (int, string, string) {
+// coastalBoostElevation is the elevation in meters below which addresses in
+// coastal municipalities get coastalBoost added to the overall score.
+const (
+	coastalBoostElevation = 5.0
+	coastalBoost          = 10
+)
+
+// calculateRisk computes the overall risk score and a summary in the
+// language of loc.
+// uncertainty is the vertical uncertainty of elevation in meters; near the
+// coastal threshold, half the boost is applied.
+func calculateRisk(hazards []HazardResult, elevation *float64, uncertainty float64, kommunenummer string, loc localizer) (int, string, string) {
 	maxScore := 0
 	for _, h := range hazards {
 		if h.Score > maxScore {
@@ -12,30 +22,56 @@ func calculateRisk(hazards []HazardResult, elevation *float64, kommunenummer str
 	}
 
 	// Coastal low-elevation boost
-	if elevation != nil && *elevation < 5 && isCoastalMunicipality(kommunenummer) {
-		maxScore += 10
-		if maxScore > 100 {
-			maxScore = 100
+	var elevationNote string
+	if elevation != nil && isCoastalMunicipality(kommunenummer) {
+		switch compareElevation(*elevation, coastalBoostElevation, uncertainty) {
+		case elevationBelow:
+			maxScore += coastalBoost
+		case elevationBorderline:
+			maxScore += coastalBoost / 2
+			elevationNote = loc.Sprintf("Høyden (%.1f moh., ±%.1f m) er for usikker til å avgjøre om adressen ligger under %.0f moh.", *elevation, uncertainty, coastalBoostElevation)
 		}
+		maxScore = min(maxScore, 100)
 	}
 
 	level := scoreLevel(maxScore)
-	summary := scoreSummary(maxScore, level)
+	summary := scoreSummary(maxScore, level, loc)
+	if elevationNote != "" {
+		summary += " " + elevationNote
+	}
+	if note := coverageNote(hazards, loc); note != "" {
+		summary += " " + note
+	}
 
 	return maxScore, level, summary
 }
 
-// scoreSummary returns a Norwegian human-readable summary for the score.
-func scoreSummary(score int, level string) string {
+// coverageNote explains which hazard maps do not cover the address, so a
+// low score is not mistaken for confirmed safety.
+func coverageNote(hazards []HazardResult, loc localizer) string {
+	var unmapped []string
+	for _, h := range hazards {
+		if h.Coverage == coverageNotMapped {
+			unmapped = append(unmapped, strings.ToLower(h.Name))
+		}
+	}
+	if len(unmapped) == 0 {
+		return ""
+	}
+	return loc.Sprintf("NVE har ikke kartlagt %s her, så faren for disse er ukjent.", loc.Join(unmapped))
+}
+
+// scoreSummary returns a human-readable summary for the score.
+func scoreSummary(score int, level string, loc localizer) string {
 	switch level {
 	case "low":
-		return "Adressen har lav risiko for naturfare. Ingen kjente faresoner er registrert her."
+		return loc.T("Adressen har lav risiko for naturfare. Ingen kjente faresoner er registrert her.")
 	case "medium":
-		return "Adressen har moderat risiko. Det er registrert noen aktsomhetsområder i nærheten."
+		return loc.T("Adressen har moderat risiko. Det er registrert noen aktsomhetsområder i nærheten.")
 	case "high":
-		return fmt.Sprintf("Adressen har høy risiko (score %d/100). Én eller flere naturfaresoner er registrert på dette punktet.", score)
+		return loc.Sprintf("Adressen har høy risiko (score %d/100). Én eller flere naturfaresoner er registrert på dette punktet.", score)
 	case "very_high":
-		return fmt.Sprintf("Adressen har svært høy risiko (score %d/100). Flere alvorlige naturfaresoner er registrert. Vurder å innhente profesjonell vurdering.", score)
+		return loc.Sprintf("Adressen har svært høy risiko (score %d/100). Flere alvorlige naturfaresoner er registrert. Vurder å innhente profesjonell vurdering.", score)
 	default:
 		return ""
 	}
@@ -11,12 +11,18 @@ import (
 )
 
 // newMux sets up the HTTP router with middleware and static file serving.
-func newMux(staticFS fs.FS, cache *Cache) http.Handler {
+// store may be nil, which disables assessment storage.
+func newMux(staticFS fs.FS, cache *Cache, store *Store) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /api/search", handleSearch)
-	mux.HandleFunc("GET /api/risk", handleRisk(cache))
-	mux.Handle("GET /", http.FileServerFS(staticFS))
+	mux.HandleFunc("GET /api/risk", handleRisk(cache, store))
+	mux.HandleFunc("GET /api/report.pdf", handleReport(cache, store))
+	mux.HandleFunc("GET /api/assessments/{id}", handleAssessment(store))
+	mux.HandleFunc("GET /api/events", handleEvents(cache))
+	mux.HandleFunc("GET /api/events/summary", handleEventSummary(cache))
+	mux.HandleFunc("GET /api/kommune/{knr}", handleKommune(cache))
+	mux.Handle("GET /", handleIndex(staticFS, cache))
 
 	return withLogging(withRecovery(mux))
 }
@@ -34,7 +40,7 @@ func withRecovery(next http.Handler) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("panic: %v", err)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				writeError(w, http.StatusInternalServerError, codeInternal, "internal server error")
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -45,11 +51,31 @@ var httpClient = &http.Client{
 	Timeout: 10 * time.Second,
 }
 
+// notFoundKey is the cache key marking a URL as returning 404.
+func notFoundKey(url string) string {
+	return "404:" + url
+}
+
 // cachedGet fetches a URL with caching. Returns the response body bytes.
+// Failures are returned as *upstreamError. A 404 is cached for ttl like a success, since
+// several APIs use it for "no data here"; other failures are not cached.
+// Successful and 404 responses are recorded in the context's fetch log, if any.
 func cachedGet(ctx context.Context, cache *Cache, url string, ttl time.Duration) ([]byte, error) {
-	if data, ok := cache.Get(url); ok {
+	return cachedGetValid(ctx, cache, url, ttl, nil)
+}
+
+// cachedGetValid is cachedGet for services that report errors with status
+// 200: a fresh body is cached only if valid accepts it, and is otherwise
+// an invalid data error.
+func cachedGetValid(ctx context.Context, cache *Cache, url string, ttl time.Duration, valid func([]byte) error) ([]byte, error) {
+	if data, storedAt, ok := cache.GetStored(url); ok {
+		recordFetch(ctx, url, http.StatusOK, storedAt, data)
 		return data, nil
 	}
+	if _, storedAt, ok := cache.GetStored(notFoundKey(url)); ok {
+		recordFetch(ctx, url, http.StatusNotFound, storedAt, nil)
+		return nil, statusCodeError(url, http.StatusNotFound)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -59,19 +85,30 @@ func cachedGet(ctx context.Context, cache *Cache, url string, ttl time.Duration)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("fetching %s: %w", url, err)
+		return nil, recordFailure(ctx, 0, transportError(url, err))
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		cache.Set(notFoundKey(url), nil, ttl)
+		recordFetch(ctx, url, http.StatusNotFound, time.Now(), nil)
+		return nil, statusCodeError(url, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetching %s: status %d", url, resp.StatusCode)
+		return nil, recordFailure(ctx, resp.StatusCode, statusCodeError(url, resp.StatusCode))
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20)) // 2 MB limit
 	if err != nil {
-		return nil, fmt.Errorf("reading response from %s: %w", url, err)
+		return nil, recordFailure(ctx, resp.StatusCode, transportError(url, err))
+	}
+	if valid != nil {
+		if err := valid(body); err != nil {
+			return nil, recordFailure(ctx, resp.StatusCode, &upstreamError{Code: codeInvalidData, URL: url, Err: err})
+		}
 	}
 
 	cache.Set(url, body, ttl)
+	recordFetch(ctx, url, http.StatusOK, time.Now(), body)
 	return body, nil
 }
@@ -2,19 +2,24 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"math"
-	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const skredHendelserURL = "https://gis3.nve.no/map/rest/services/Mapservices/SkredHendelser/MapServer/0/query"
+const flomHendelserURL = "https://gis3.nve.no/map/rest/services/Mapservices/FlomHendelser/MapServer/0/query"
+const kvikkleireHendelserURL = "https://gis3.nve.no/map/rest/services/Mapservices/KvikkleireskredHendelser/MapServer/0/query"
 const skredSearchRadiusKm = 1.0
-const skredMaxResults = 50
+
+// Event layer results are paged: skredPageSize events per request, at
+// most skredMaxPages requests per source and assessment.
+const skredPageSize = 50
+const skredMaxPages = 20
 
 // skredTypeNames maps NVE's numeric skredType codes to Norwegian names.
 // Source: https://register.geonorge.no/sosi-kodelister/skred/skredtypedetaljert
@@ -46,168 +51,382 @@ var skredTypeNames = map[int]string{
 	190: "Skred (type ikke angitt)",
 }
 
-// skredHendelserResponse is the ArcGIS JSON response for the SkredHendelser layer.
-type skredHendelserResponse struct {
-	Features []skredHendelserFeature `json:"features"`
+// eventLayerResponse is the ArcGIS JSON response for NVE's point event
+// layers (SkredHendelser, FlomHendelser, KvikkleireskredHendelser).
+type eventLayerResponse struct {
+	Features              []eventLayerFeature `json:"features"`
+	ExceededTransferLimit bool                `json:"exceededTransferLimit"`
 }
 
-type skredHendelserFeature struct {
-	Attributes map[string]any     `json:"attributes"`
-	Geometry   *skredPointGeometry `json:"geometry"`
+type eventLayerFeature struct {
+	Attributes map[string]any `json:"attributes"`
+	Geometry   *pointGeometry `json:"geometry"`
 }
 
-type skredPointGeometry struct {
+type pointGeometry struct {
 	X float64 `json:"x"`
 	Y float64 `json:"y"`
 }
 
-// getSkredHendelser queries NVE's historical landslide event database
-// within a bounding box, filters to a circular radius, scores, and returns
-// both a HazardResult and the individual events for display.
-func getSkredHendelser(ctx context.Context, cache *Cache, lat, lon float64) (HazardResult, []HistoricalEvent) {
-	h := HazardResult{
-		ID:   "historical_landslides",
-		Name: "Historiske skredhendelser",
+// getHistoricalEvents queries an NVE event layer within a circular radius
+// and looks up each event's elevation. Scoring happens in historicalHazard
+// once the static hazard zones are known. The int result is as for
+// fetchHistoricalEvents.
+func getHistoricalEvents(ctx context.Context, cache *Cache, src eventSource, lat, lon float64) ([]HistoricalEvent, int, error) {
+	events, truncatedAt, err := fetchHistoricalEvents(ctx, cache, src, lat, lon)
+	if err != nil {
+		return nil, 0, err
 	}
+	annotateEventElevations(ctx, cache, events)
+	return events, truncatedAt, nil
+}
 
-	events, err := fetchSkredHendelser(ctx, cache, lat, lon)
+// annotateEventElevations sets the elevation of the closest events. Events
+// must be sorted closest first. On failure, scoring falls back to ignoring
+// slope position.
+func annotateEventElevations(ctx context.Context, cache *Cache, events []HistoricalEvent) {
+	n := min(len(events), skredElevationLookups)
+	points := make([][2]float64, n)
+	for i := range n {
+		points[i] = [2]float64{events[i].Latitude, events[i].Longitude}
+	}
+	elevations, err := getElevations(ctx, cache, points)
 	if err != nil {
-		log.Printf("skredhendelser error: %v", err)
-		h.Error = "Kunne ikke hente data"
-		h.Level = "unknown"
-		return h, nil
+		log.Printf("event elevation error: %v", err)
+		return
+	}
+	for i, z := range elevations {
+		events[i].Elevation = z
+	}
+}
+
+// historicalKind describes one historical hazard result and its wording,
+// in Bokmål.
+type historicalKind struct {
+	ID       string
+	Name     string
+	Singular string // "1 <Singular> innenfor 1 km"
+	Plural   string // "3 <Plural> innenfor 1 km"
+	None     string // description when there are no events
+}
+
+var (
+	kindLandslides = historicalKind{
+		ID:       "historical_landslides",
+		Name:     "Historiske skredhendelser",
+		Singular: "historisk skredhendelse",
+		Plural:   "historiske skredhendelser",
+		None:     "Ingen registrerte skredhendelser",
+	}
+	kindFloods = historicalKind{
+		ID:       "historical_floods",
+		Name:     "Historiske flomhendelser",
+		Singular: "historisk flomhendelse",
+		Plural:   "historiske flomhendelser",
+		None:     "Ingen registrerte flomhendelser",
+	}
+	kindQuickClay = historicalKind{
+		ID:       "historical_quick_clay",
+		Name:     "Historiske kvikkleireskred",
+		Singular: "registrert kvikkleireskred",
+		Plural:   "registrerte kvikkleireskred",
+		None:     "Ingen registrerte kvikkleireskred",
+	}
+)
+
+// historicalHazard scores historical events against the address and returns
+// the HazardResult. hazards are the static hazard results used to judge
+// whether each event's type is relevant to the address; events are updated
+// in place with their weights and contributions. truncatedAt is the number
+// of events fetched when paging stopped, or 0 if the list is complete.
+func historicalHazard(kind historicalKind, events []HistoricalEvent, truncatedAt int, fetchErr error, hazards []HazardResult, elevation *float64, loc localizer) HazardResult {
+	h := HazardResult{
+		ID:   kind.ID,
+		Name: loc.T(kind.Name),
+	}
+
+	if fetchErr != nil {
+		log.Printf("%s error: %v", kind.ID, fetchErr)
+		h.setError(fetchErr, loc)
+		return h
 	}
 
 	if len(events) == 0 {
 		h.Score = 0
 		h.Level = scoreLevel(0)
-		h.Description = "Ingen registrerte skredhendelser"
-		h.Details = "Ingen historiske skredhendelser er registrert innenfor 1 km."
-		return h, nil
+		h.Description = loc.T(kind.None)
+		h.Details = loc.Sprintf("Ingen %s innenfor 1 km.", loc.T(kind.Plural))
+		return h
 	}
 
-	score := scoreHistoricalEvents(events)
+	score := scoreHistoricalEvents(events, hazards, elevation)
 	h.Score = score
 	h.Level = scoreLevel(score)
 
 	if len(events) == 1 {
-		h.Description = "1 historisk skredhendelse innenfor 1 km"
+		h.Description = loc.Sprintf("1 %s innenfor 1 km", loc.T(kind.Singular))
 	} else {
-		h.Description = fmt.Sprintf("%d historiske skredhendelser innenfor 1 km", len(events))
+		h.Description = loc.Sprintf("%d %s innenfor 1 km", len(events), loc.T(kind.Plural))
 	}
 
 	// Build details summary
 	var parts []string
 	for i, e := range events {
 		if i >= 3 {
-			parts = append(parts, fmt.Sprintf("og %d til.", len(events)-3))
+			parts = append(parts, loc.Sprintf("og %d til.", len(events)-3))
 			break
 		}
-		detail := e.Type
+		detail := loc.T(e.Type)
 		if e.Date != "" {
 			detail += " (" + e.Date + ")"
 		}
-		detail += fmt.Sprintf(", %d m unna", e.DistanceMeters)
+		detail += loc.Sprintf(", %d m unna", e.DistanceMeters)
+		if label, ok := positionLabels[e.Position]; ok {
+			detail += ", " + loc.T(label)
+		}
 		parts = append(parts, detail)
 	}
 	h.Details = strings.Join(parts, ". ")
+	if truncatedAt > 0 {
+		h.Details += loc.Sprintf(" Listen er avkortet etter %d hendelser.", truncatedAt)
+	}
 
-	return h, events
+	return h
 }
 
-// fetchSkredHendelser queries the NVE SkredHendelser API with a bounding box,
-// then filters to a circular radius and deduplicates by skredID.
-func fetchSkredHendelser(ctx context.Context, cache *Cache, lat, lon float64) ([]HistoricalEvent, error) {
-	dLat := skredSearchRadiusKm / 111.0
-	dLon := skredSearchRadiusKm / (111.0 * math.Cos(lat*math.Pi/180.0))
-
-	bbox := fmt.Sprintf("%f,%f,%f,%f", lon-dLon, lat-dLat, lon+dLon, lat+dLat)
-	params := url.Values{
-		"geometry":          {bbox},
-		"geometryType":      {"esriGeometryEnvelope"},
-		"inSR":              {"4326"},
-		"outSR":             {"4326"},
-		"spatialRel":        {"esriSpatialRelIntersects"},
-		"outFields":         {"*"},
-		"returnGeometry":    {"true"},
-		"resultRecordCount": {fmt.Sprintf("%d", skredMaxResults)},
-		"f":                 {"json"},
-	}
-	u := skredHendelserURL + "?" + params.Encode()
-
-	data, err := cachedGet(ctx, cache, u, nveCacheTTL)
+// fetchHistoricalEvents queries an NVE event layer with a server-side
+// distance buffer, paging through all results, and deduplicates by ID.
+// If paging stopped at skredMaxPages before the upstream ran out of events,
+// the int result is the number of events fetched, before the distance
+// check below; otherwise it is 0.
+func fetchHistoricalEvents(ctx context.Context, cache *Cache, src eventSource, lat, lon float64) ([]HistoricalEvent, int, error) {
+	q := eventQuery{
+		Source:  src,
+		Lat:     lat,
+		Lon:     lon,
+		RadiusM: int(skredSearchRadiusKm * 1000),
+		Limit:   skredPageSize,
+	}
+
+	all, truncated, err := fetchAllEvents(ctx, cache, q, skredMaxPages)
 	if err != nil {
-		return nil, fmt.Errorf("skredhendelser fetch: %w", err)
+		return nil, 0, fmt.Errorf("%s fetch: %w", src.ID, err)
+	}
+	truncatedAt := 0
+	if truncated {
+		truncatedAt = len(all)
 	}
 
-	var result skredHendelserResponse
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("skredhendelser decode: %w", err)
+	// The server-side buffer is computed in the layer's projection;
+	// re-check against the great-circle distance.
+	events := all[:0]
+	for _, e := range all {
+		if e.DistanceMeters <= skredSearchRadiusKm*1000 {
+			events = append(events, e)
+		}
 	}
 
-	seen := make(map[string]bool)
-	var events []HistoricalEvent
+	sortByDistance(events)
+	return events, truncatedAt, nil
+}
 
-	for _, f := range result.Features {
-		if f.Geometry == nil {
-			continue
-		}
+// sortByDistance sorts events closest first.
+func sortByDistance(events []HistoricalEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].DistanceMeters < events[j].DistanceMeters
+	})
+}
 
-		// Deduplicate: use skredID (GUID) when present, otherwise fall back
-		// to a coordinate-based key (5 decimal places ~ 1 m precision).
-		dedupKey := fmt.Sprintf("%.5f,%.5f", f.Geometry.X, f.Geometry.Y)
-		if id := attrString(f.Attributes, "skredID"); id != "" {
-			dedupKey = id
-		}
-		if seen[dedupKey] {
+// splitQuickClay moves quick clay and clay slides out of the landslide list
+// and merges them into the quick clay registry events, skipping events
+// present in both. Moved events take the quick clay source, so that they
+// are listed and drawn with the others. Both lists stay sorted by distance.
+func splitQuickClay(landslides, quickClay []HistoricalEvent) ([]HistoricalEvent, []HistoricalEvent) {
+	seen := make(map[string]bool, len(quickClay))
+	for _, e := range quickClay {
+		seen[eventKey(e)] = true
+	}
+
+	var rest []HistoricalEvent
+	merged := quickClay
+	for _, e := range landslides {
+		if skredTypeHazard[e.TypeCode] != "quick_clay" {
+			rest = append(rest, e)
 			continue
 		}
-		seen[dedupKey] = true
+		if !seen[eventKey(e)] {
+			e.Source = sourceQuickClay.ID
+			merged = append(merged, e)
+		}
+	}
 
-		evtLat := f.Geometry.Y
-		evtLon := f.Geometry.X
+	sortByDistance(merged)
+	return rest, merged
+}
 
-		dist := haversineMeters(lat, lon, evtLat, evtLon)
-		if dist > skredSearchRadiusKm*1000 {
-			continue
-		}
+// eventKey identifies an event for deduplication: its ID when present,
+// otherwise a coordinate-based key (5 decimal places ~ 1 m precision).
+func eventKey(e HistoricalEvent) string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return fmt.Sprintf("%.5f,%.5f", e.Longitude, e.Latitude)
+}
 
-		e := HistoricalEvent{
-			Type:           resolveSkredType(f.Attributes),
-			Date:           parseSkredDate(f.Attributes),
-			Location:       attrString(f.Attributes, "stedsnavn", "sted"),
-			BuildingDamage: attrString(f.Attributes, "bygnSkadet") == "Ja",
-			RoadDamage:     attrString(f.Attributes, "vegSkadet") == "Ja",
-			Fatalities:     attrIntVal(f.Attributes, "totAntPersOmkommet"),
-			Description:    attrString(f.Attributes, "beskrivelse", "hendelseBeskrivelse"),
-			Latitude:       evtLat,
-			Longitude:      evtLon,
-			DistanceMeters: int(dist),
-		}
+// eventFromFeature converts an event layer feature with geometry into a
+// HistoricalEvent, with distance measured from lat/lon.
+func eventFromFeature(f eventLayerFeature, src eventSource, lat, lon float64) HistoricalEvent {
+	evtLat := f.Geometry.Y
+	evtLon := f.Geometry.X
+
+	e := HistoricalEvent{
+		ID:             attrString(f.Attributes, "skredID", "flomID", "hendelseID"),
+		Source:         src.ID,
+		Type:           resolveSkredType(f.Attributes),
+		TypeCode:       attrIntVal(f.Attributes, "skredType"),
+		Date:           attrDate(f.Attributes, src.DateField, "skredTidspunkt", "dato", "skredDato"),
+		Location:       attrString(f.Attributes, "stedsnavn", "sted", "vassdragNavn"),
+		BuildingDamage: attrString(f.Attributes, "bygnSkadet") == "Ja",
+		RoadDamage:     attrString(f.Attributes, "vegSkadet") == "Ja",
+		Fatalities:     attrIntVal(f.Attributes, "totAntPersOmkommet"),
+		Description:    attrString(f.Attributes, "beskrivelse", "hendelseBeskrivelse"),
+		Latitude:       evtLat,
+		Longitude:      evtLon,
+		DistanceMeters: int(haversineMeters(lat, lon, evtLat, evtLon)),
+	}
 
-		if e.Type == "" {
-			e.Type = "Skred (ukjent type)"
-		}
+	if e.Type == "" {
+		e.Type = src.DefaultType
+	}
+	return e
+}
 
-		events = append(events, e)
+// skredTypeHazard maps skredType codes to the static hazard whose
+// aktsomhet zone covers that kind of event. Codes without an entry (e.g.
+// unspecified or submarine slides) have unknown relevance.
+var skredTypeHazard = map[int]string{
+	110: "rock_fall", 111: "rock_fall", 112: "rock_fall", 113: "rock_fall",
+	130: "avalanche", 131: "avalanche", 132: "avalanche", 133: "avalanche",
+	134: "avalanche", 135: "avalanche", 136: "avalanche", 137: "avalanche",
+	138: "avalanche", 139: "avalanche", 150: "avalanche", 151: "avalanche",
+	171: "avalanche",
+	140: "landslide", 142: "landslide", 144: "landslide", 160: "landslide",
+	141: "quick_clay", 143: "quick_clay",
+}
+
+// Event relevance to the address, from whether the address lies in the
+// aktsomhet zone for the event's type.
+const (
+	relevanceInZone  = "in_zone"
+	relevanceOutside = "outside_zone"
+	relevanceUnknown = "unknown"
+)
+
+var relevanceWeight = map[string]float64{
+	relevanceInZone:  1.0,
+	relevanceOutside: 0.5,
+	relevanceUnknown: 0.75,
+}
+
+// Event position relative to the address. Slides run downhill, so events
+// upslope can reach the address while events well below it rarely can.
+const (
+	positionUpslope   = "upslope"
+	positionLevel     = "level"
+	positionDownslope = "downslope"
+
+	// slopeThresholdM is the height difference treated as level ground,
+	// allowing for terrain model accuracy and event position uncertainty.
+	slopeThresholdM = 20.0
+)
+
+var positionWeight = map[string]float64{
+	positionUpslope:   1.2,
+	positionLevel:     1.0,
+	positionDownslope: 0.4,
+}
+
+var positionLabels = map[string]string{
+	positionUpslope:   "ovenfor adressen",
+	positionDownslope: "nedenfor adressen",
+}
+
+// eventHazards returns the static hazards whose zones cover the kind of
+// event, or nil when unknown.
+func eventHazards(e HistoricalEvent) []string {
+	switch e.Source {
+	case sourceFlood.ID:
+		return []string{"flood_zones", "flood_awareness"}
+	case sourceQuickClay.ID:
+		return []string{"quick_clay"}
+	}
+	if id, ok := skredTypeHazard[e.TypeCode]; ok {
+		return []string{id}
 	}
+	return nil
+}
 
-	// Sort by distance (closest first)
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].DistanceMeters < events[j].DistanceMeters
-	})
+// isQuickClayEvent reports whether the event is a quick clay or clay slide.
+func isQuickClayEvent(e HistoricalEvent) bool {
+	return e.Source == sourceQuickClay.ID || skredTypeHazard[e.TypeCode] == "quick_clay"
+}
+
+// eventRelevance is in_zone if the address is exposed to any hazard
+// covering the event, outside_zone if all of them were checked and clear,
+// and unknown otherwise.
+func eventRelevance(e HistoricalEvent, exposure map[string]string) string {
+	ids := eventHazards(e)
+	if len(ids) == 0 {
+		return relevanceUnknown
+	}
+	result := relevanceOutside
+	for _, id := range ids {
+		switch exposure[id] {
+		case relevanceInZone:
+			return relevanceInZone
+		case relevanceOutside:
+		default:
+			result = relevanceUnknown
+		}
+	}
+	return result
+}
 
-	return events, nil
+// hazardExposure classes the address per hazard ID for eventRelevance. A
+// zero score only clears the address where NVE has mapped the hazard in
+// detail; unmapped areas and the coarse awareness maps leave it unknown.
+func hazardExposure(hazards []HazardResult) map[string]string {
+	exposure := make(map[string]string, len(hazards))
+	for _, h := range hazards {
+		switch {
+		case h.Error != "":
+			exposure[h.ID] = relevanceUnknown
+		case h.Score > 0:
+			exposure[h.ID] = relevanceInZone
+		case h.Coverage == coverageNotMapped, h.Coverage == coverageAwareness:
+			exposure[h.ID] = relevanceUnknown
+		default:
+			exposure[h.ID] = relevanceOutside
+		}
+	}
+	return exposure
 }
 
+// skredElevationLookups caps how many of the closest events get an
+// elevation lookup.
+const skredElevationLookups = 50
+
 // scoreHistoricalEvents computes an aggregate score from individual events.
-func scoreHistoricalEvents(events []HistoricalEvent) int {
+// Each event's base score is weighted by its type's relevance to the address
+// and its position up- or downslope; events are updated with their weight,
+// weighted score and contribution to the total.
+func scoreHistoricalEvents(events []HistoricalEvent, hazards []HazardResult, elevation *float64) int {
 	now := time.Now()
-
-	var scores []int
+	exposure := hazardExposure(hazards)
 	hasFatalitiesClose := false
 
-	for _, e := range events {
+	for i := range events {
+		e := &events[i]
 		s := 10 // base: event exists within 1km
 
 		if e.BuildingDamage {
@@ -218,9 +437,6 @@ func scoreHistoricalEvents(events []HistoricalEvent) int {
 		}
 		if e.Fatalities > 0 {
 			s += 30
-			if e.DistanceMeters < 200 {
-				hasFatalitiesClose = true
-			}
 		}
 
 		// Recency bonus
@@ -245,16 +461,49 @@ func scoreHistoricalEvents(events []HistoricalEvent) int {
 		if s > 100 {
 			s = 100
 		}
-		scores = append(scores, s)
+
+		e.Relevance = eventRelevance(*e, exposure)
+		weight := relevanceWeight[e.Relevance]
+
+		// Quick clay slides retrogress and can spread upslope, so position
+		// does not apply to them.
+		if elevation != nil && e.Elevation != nil && !isQuickClayEvent(*e) {
+			diff := math.Round((*e.Elevation-*elevation)*10) / 10
+			e.ElevationDiff = &diff
+			switch {
+			case diff > slopeThresholdM:
+				e.Position = positionUpslope
+			case diff < -slopeThresholdM:
+				e.Position = positionDownslope
+			default:
+				e.Position = positionLevel
+			}
+			weight *= positionWeight[e.Position]
+		}
+
+		e.Weight = math.Round(weight*100) / 100
+		e.Score = min(100, int(float64(s)*weight+0.5))
+
+		if e.Fatalities > 0 && e.DistanceMeters < 200 && weight >= 0.75 {
+			hasFatalitiesClose = true
+		}
 	}
 
-	// Sort individual scores descending for diminishing returns
-	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+	// Sort by weighted score descending for diminishing returns, keeping
+	// the caller's (distance) order intact.
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return events[order[a]].Score > events[order[b]].Score
+	})
 
 	total := 0.0
-	for i, s := range scores {
-		weight := 1.0 / math.Pow(2, float64(i))
-		total += float64(s) * weight
+	for rank, i := range order {
+		c := float64(events[i].Score) / math.Pow(2, float64(rank))
+		events[i].Contribution = math.Round(c*10) / 10
+		total += c
 	}
 
 	result := int(total)
@@ -262,7 +511,7 @@ func scoreHistoricalEvents(events []HistoricalEvent) int {
 		result = 85
 	}
 
-	// Floor at 75 if fatalities within 200m
+	// Floor at 75 if a relevant event within 200m had fatalities
 	if hasFatalitiesClose && result < 75 {
 		result = 75
 	}
@@ -300,20 +549,19 @@ func resolveSkredType(attrs map[string]any) string {
 	return ""
 }
 
-// parseSkredDate extracts and formats the event date from attributes.
-// NVE uses epoch milliseconds or date strings.
-func parseSkredDate(attrs map[string]any) string {
-	for _, key := range []string{"skredTidspunkt", "dato", "skredDato"} {
+// attrDate returns the first date found for the given keys, formatted as
+// YYYY-MM-DD. ArcGIS dates are epoch milliseconds or date strings; only a
+// missing or null value means no date.
+func attrDate(attrs map[string]any, keys ...string) string {
+	for _, key := range keys {
 		v, ok := attrs[key]
 		if !ok || v == nil {
 			continue
 		}
 		switch val := v.(type) {
 		case float64:
-			if val > 0 {
-				t := time.UnixMilli(int64(val))
-				return t.Format("2006-01-02")
-			}
+			// Epoch milliseconds; dates before 1970 are negative.
+			return time.UnixMilli(int64(val)).Format("2006-01-02")
 		case string:
 			if val != "" {
 				if t, err := time.Parse("2006-01-02", val); err == nil {
@@ -353,3 +601,19 @@ func attrIntVal(attrs map[string]any, keys ...string) int {
 	}
 	return 0
 }
+
+// attrFloat returns the first numeric value found for the given keys.
+// Numeric strings are accepted since some NVE layers store numbers as text.
+func attrFloat(attrs map[string]any, keys ...string) (float64, bool) {
+	for _, k := range keys {
+		switch v := attrs[k].(type) {
+		case float64:
+			return v, true
+		case string:
+			if f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64); err == nil {
+				return f, true
+			}
+		}
+	}
+	return 0, false
+}
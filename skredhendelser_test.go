@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHazardExposure(t *testing.T) {
+	hazards := []HazardResult{
+		{ID: "flood_zones", Coverage: coverageMapped, Score: 40},
+		{ID: "combined_hazard", Coverage: coverageMapped},
+		{ID: "quick_clay", Coverage: coverageNotMapped},
+		{ID: "avalanche", Coverage: coverageAwareness},
+		{ID: "rock_fall", Coverage: coverageAwareness, Score: 65},
+		{ID: "landslide", Coverage: coverageAwareness, Error: "timeout"},
+	}
+	want := map[string]string{
+		"flood_zones":     relevanceInZone,
+		"combined_hazard": relevanceOutside,
+		"quick_clay":      relevanceUnknown,
+		"avalanche":       relevanceUnknown,
+		"rock_fall":       relevanceInZone,
+		"landslide":       relevanceUnknown,
+	}
+	got := hazardExposure(hazards)
+	for id, w := range want {
+		if got[id] != w {
+			t.Errorf("%s: got %q, want %q", id, got[id], w)
+		}
+	}
+}
+
+func TestScoreHistoricalEventsWeight(t *testing.T) {
+	const address = 100.0
+	tests := []struct {
+		name      string
+		coverage  string
+		score     int
+		typeCode  int
+		elevation *float64
+
+		relevance string
+		position  string
+		weight    float64
+	}{
+		{"in zone, level", coverageAwareness, 70, 130, ptr(address + 10), relevanceInZone, positionLevel, 1.0},
+		{"in zone, upslope", coverageAwareness, 70, 130, ptr(address + 30), relevanceInZone, positionUpslope, 1.2},
+		{"in zone, downslope", coverageAwareness, 70, 130, ptr(address - 30), relevanceInZone, positionDownslope, 0.4},
+		{"in zone, no elevation", coverageAwareness, 70, 130, nil, relevanceInZone, "", 1.0},
+		{"outside, level", coverageMapped, 0, 130, ptr(address), relevanceOutside, positionLevel, 0.5},
+		{"outside, upslope", coverageMapped, 0, 130, ptr(address + 20.1), relevanceOutside, positionUpslope, 0.6},
+		{"outside, downslope", coverageMapped, 0, 130, ptr(address - 25), relevanceOutside, positionDownslope, 0.2},
+		{"unmapped, level", coverageNotMapped, 0, 130, ptr(address - 20), relevanceUnknown, positionLevel, 0.75},
+		{"awareness only, upslope", coverageAwareness, 0, 130, ptr(address + 50), relevanceUnknown, positionUpslope, 0.9},
+		{"untyped, downslope", coverageAwareness, 70, 190, ptr(address - 50), relevanceUnknown, positionDownslope, 0.3},
+		{"quick clay ignores position", coverageMapped, 40, 141, ptr(address - 50), relevanceInZone, "", 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hazardID := skredTypeHazard[tt.typeCode]
+			if hazardID == "" {
+				hazardID = "avalanche"
+			}
+			hazards := []HazardResult{{ID: hazardID, Coverage: tt.coverage, Score: tt.score}}
+			events := []HistoricalEvent{{Source: sourceSkred.ID, TypeCode: tt.typeCode, DistanceMeters: 800, Elevation: tt.elevation}}
+			elevation := address
+			scoreHistoricalEvents(events, hazards, &elevation)
+
+			e := events[0]
+			if e.Relevance != tt.relevance || e.Position != tt.position || e.Weight != tt.weight {
+				t.Errorf("got %s/%s weight %v, want %s/%s weight %v", e.Relevance, e.Position, e.Weight, tt.relevance, tt.position, tt.weight)
+			}
+			// Base score 10: no damage, no date and over 500 m away.
+			if want := int(10*tt.weight + 0.5); e.Score != want {
+				t.Errorf("score %d, want %d", e.Score, want)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func TestSplitQuickClay(t *testing.T) {
+	landslides := []HistoricalEvent{
+		{ID: "1", Source: sourceSkred.ID, TypeCode: 140, DistanceMeters: 100},
+		{ID: "2", Source: sourceSkred.ID, TypeCode: 141, DistanceMeters: 300},
+		{ID: "3", Source: sourceSkred.ID, TypeCode: 143, DistanceMeters: 50},
+		{ID: "4", Source: sourceSkred.ID, TypeCode: 130, DistanceMeters: 200},
+	}
+	quickClay := []HistoricalEvent{
+		{ID: "3", Source: sourceQuickClay.ID, DistanceMeters: 50},
+		{Source: sourceQuickClay.ID, DistanceMeters: 150, Latitude: 63.4, Longitude: 10.4},
+	}
+
+	rest, merged := splitQuickClay(landslides, quickClay)
+
+	var restIDs []string
+	for _, e := range rest {
+		restIDs = append(restIDs, e.ID)
+	}
+	if len(restIDs) != 2 || restIDs[0] != "1" || restIDs[1] != "4" {
+		t.Errorf("rest %v, want [1 4]", restIDs)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("merged %d events, want 3 (duplicate skipped)", len(merged))
+	}
+	for i, e := range merged {
+		if e.Source != sourceQuickClay.ID {
+			t.Errorf("merged[%d] source %q", i, e.Source)
+		}
+		if i > 0 && merged[i-1].DistanceMeters > e.DistanceMeters {
+			t.Errorf("merged not sorted by distance: %d before %d", merged[i-1].DistanceMeters, e.DistanceMeters)
+		}
+	}
+	if merged[2].ID != "2" {
+		t.Errorf("farthest merged event %q, want 2", merged[2].ID)
+	}
+}
+
+func TestHistoricalHazardTruncationNote(t *testing.T) {
+	loc := newLocalizer(langBokmal)
+	events := func() []HistoricalEvent {
+		return []HistoricalEvent{
+			{Source: sourceSkred.ID, Type: "Steinsprang", TypeCode: 110, DistanceMeters: 200},
+			{Source: sourceSkred.ID, Type: "Steinsprang", TypeCode: 110, DistanceMeters: 900},
+		}
+	}
+
+	// The note gives the number fetched, not the number left within 1 km.
+	h := historicalHazard(kindLandslides, events(), 1000, nil, nil, nil, loc)
+	if !strings.HasSuffix(h.Details, " Listen er avkortet etter 1000 hendelser.") {
+		t.Errorf("truncated details %q", h.Details)
+	}
+	h = historicalHazard(kindLandslides, events(), 0, nil, nil, nil, loc)
+	if strings.Contains(h.Details, "avkortet") {
+		t.Errorf("complete details %q", h.Details)
+	}
+}
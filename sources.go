@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchRecord is one upstream fetch made for an assessment.
+type fetchRecord struct {
+	URL         string
+	Status      int       // HTTP status; 0 if no response was received
+	RetrievedAt time.Time // when the response was fetched, not when it was served from cache
+	SHA256      string    // of the raw response body; empty for a 404 or a failure
+	Bytes       int
+	Error       string // set when the fetch failed
+}
+
+// fetchLog collects the upstream responses used while handling a request.
+type fetchLog struct {
+	mu      sync.Mutex
+	records []fetchRecord
+}
+
+type fetchLogKey struct{}
+
+// withFetchLog returns a context in which cachedGet records every response
+// it returns.
+func withFetchLog(ctx context.Context) (context.Context, *fetchLog) {
+	fl := &fetchLog{}
+	return context.WithValue(ctx, fetchLogKey{}, fl), fl
+}
+
+// recordFetch adds a response to the context's fetch log. The body of a 404
+// is not kept, so only its status is recorded.
+func recordFetch(ctx context.Context, url string, status int, at time.Time, body []byte) {
+	fl, ok := ctx.Value(fetchLogKey{}).(*fetchLog)
+	if !ok {
+		return
+	}
+	rec := fetchRecord{URL: url, Status: status, RetrievedAt: at}
+	if status == http.StatusOK {
+		sum := sha256.Sum256(body)
+		rec.SHA256 = hex.EncodeToString(sum[:])
+		rec.Bytes = len(body)
+	}
+	fl.mu.Lock()
+	fl.records = append(fl.records, rec)
+	fl.mu.Unlock()
+}
+
+// recordFailure adds a failed fetch to the context's fetch log, with the
+// response status if there was one, and returns err. Failures are kept so
+// that a stored assessment shows which sources it went without.
+func recordFailure(ctx context.Context, status int, err *upstreamError) error {
+	fl, ok := ctx.Value(fetchLogKey{}).(*fetchLog)
+	if !ok {
+		return err
+	}
+	msg := err.Code
+	if err.Err != nil {
+		msg += ": " + err.Err.Error()
+	}
+	fl.mu.Lock()
+	fl.records = append(fl.records, fetchRecord{URL: err.URL, Status: status, RetrievedAt: time.Now(), Error: msg})
+	fl.mu.Unlock()
+	return err
+}
+
+// responses returns each distinct upstream response once, in the order
+// they were first used.
+func (l *fetchLog) responses() []UpstreamResponse {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := make(map[string]bool)
+	out := []UpstreamResponse{}
+	for _, rec := range l.records {
+		key := fmt.Sprint(rec.URL, " ", rec.Status, " ", rec.SHA256, " ", rec.Error)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, UpstreamResponse{
+			URL:         rec.URL,
+			Status:      rec.Status,
+			RetrievedAt: rec.RetrievedAt.UTC().Format(time.RFC3339Nano),
+			SHA256:      rec.SHA256,
+			Bytes:       rec.Bytes,
+			Error:       rec.Error,
+		})
+	}
+	return out
+}
+
+// dataSources names upstream services by URL prefix. NVE map services not
+// listed here are named after the service itself; see sourceOf.
+var dataSources = []struct{ name, prefix string }{
+	{"Kartverket Høydedata", elevationURL},
+	{"Kartverket Adresser", geonorgeSearchURL},
+	{"Kartverket Kommuneinfo", kommuneInfoURL},
+	{"Kartverket FKB-Bygning", fkbBygningURL},
+	{"Kartverket Stormflo", stormfloBaseURL},
+	{"Kartverket Topografisk kart", topoWMSURL},
+	{"MET Farevarsel", metalertsURL},
+	{"NVE Varsom flomvarsel", varsomFloodURL},
+	{"NVE Varsom jordskredvarsel", varsomLandslideURL},
+	{"NVE Varsom snøskredvarsel", varsomAvalancheURL},
+	{"NVE Skredhendelser", skredHendelserURL},
+	{"NVE Flomhendelser", flomHendelserURL},
+	{"NVE Kvikkleireskredhendelser", kvikkleireHendelserURL},
+	{"NVE Elvenett", elvenettURL},
+	{"NVE Innsjødatabase", innsjoURL},
+}
+
+// sourceOf returns the name and base URL of the service a URL belongs to.
+func sourceOf(rawURL string) (name, base string) {
+	for _, s := range dataSources {
+		if strings.HasPrefix(rawURL, s.prefix) {
+			return s.name, s.prefix
+		}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, rawURL
+	}
+	u.RawQuery = ""
+	// NVE's ArcGIS services: .../services/<name>/MapServer[/...], as REST
+	// for queries and WMS for map images.
+	if i := strings.Index(u.Path, "/MapServer"); i >= 0 && u.Host == "nve.geodataonline.no" {
+		wms := !strings.Contains(u.Path, "/rest/")
+		u.Path = u.Path[:i+len("/MapServer")]
+		parts := strings.Split(u.Path, "/")
+		name = "NVE " + parts[len(parts)-2]
+		if wms {
+			name += " WMS"
+		}
+		return name, u.String()
+	}
+	return u.Host, u.String()
+}
+
+// sources groups the log by service. A service's retrieval time is that of
+// its oldest response, since the assessment is no newer than that. Failed
+// fetches contributed no data and are left out.
+func (l *fetchLog) sources() []DataSource {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	oldest := make(map[string]time.Time)
+	out := []DataSource{}
+	for _, rec := range l.records {
+		if rec.Error != "" {
+			continue
+		}
+		name, base := sourceOf(rec.URL)
+		t, seen := oldest[base]
+		if !seen {
+			out = append(out, DataSource{Name: name, URL: base})
+		}
+		if !seen || rec.RetrievedAt.Before(t) {
+			oldest[base] = rec.RetrievedAt
+		}
+	}
+	for i := range out {
+		out[i].RetrievedAt = oldest[out[i].URL].UTC().Format(time.RFC3339)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
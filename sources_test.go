@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchLogRecordsFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"ok": true}`))
+		case "/missing":
+			http.NotFound(w, r)
+		case "/busy":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/invalid":
+			w.Write([]byte(`{"error": {"code": 400}}`))
+		}
+	}))
+	defer srv.Close()
+
+	rejectErrors := func(data []byte) error {
+		if strings.Contains(string(data), `"error"`) {
+			return errors.New("error response")
+		}
+		return nil
+	}
+	ctx, fl := withFetchLog(context.Background())
+	cache := NewCache()
+	defer cache.Close()
+	for _, path := range []string{"/ok", "/missing", "/busy", "/invalid"} {
+		cachedGetValid(ctx, cache, srv.URL+path, time.Minute, rejectErrors)
+	}
+	cachedGet(ctx, cache, "http://127.0.0.1:0/closed", time.Minute)
+
+	want := []struct {
+		path   string
+		status int
+		hash   bool
+		err    string
+	}{
+		{"/ok", 200, true, ""},
+		{"/missing", 404, false, ""},
+		{"/busy", 503, false, codeUpstreamUnavailable},
+		{"/invalid", 200, false, codeInvalidData + ": error response"},
+	}
+	got := fl.responses()
+	if len(got) != len(want)+1 {
+		t.Fatalf("%d responses, want %d: %+v", len(got), len(want)+1, got)
+	}
+	for i, w := range want {
+		r := got[i]
+		if r.URL != srv.URL+w.path || r.Status != w.status || (r.SHA256 != "") != w.hash || r.Error != w.err {
+			t.Errorf("%s: got %+v", w.path, r)
+		}
+	}
+	if r := got[len(want)]; r.Status != 0 || !strings.HasPrefix(r.Error, codeUpstreamUnavailable+": ") {
+		t.Errorf("transport error: got %+v", r)
+	}
+
+	// Failed fetches supplied no data, so they are not listed as sources.
+	// Unknown hosts are named by URL, one source per path.
+	sources := fl.sources()
+	if len(sources) != 2 {
+		t.Errorf("sources %+v, want /ok and /missing", sources)
+	}
+	for _, s := range sources {
+		if !strings.HasSuffix(s.URL, "/ok") && !strings.HasSuffix(s.URL, "/missing") {
+			t.Errorf("failed fetch listed as source: %+v", s)
+		}
+	}
+}
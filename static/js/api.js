@@ -6,7 +6,48 @@ const Api = {
     return resp.json();
   },
 
-  async risk(address) {
+  async risk(address, scenario, buildings) {
+    const params = this.riskParams(address, scenario, buildings);
+    params.set('lang', I18n.lang);
+    const resp = await fetch(`/api/risk?${params}`);
+    if (!resp.ok) throw new Error(`Risk assessment failed: ${resp.status}`);
+    return resp.json();
+  },
+
+  // reportURL links to the PDF report for the same assessment. The report
+  // is the document people keep, so it asks the server to store a snapshot.
+  reportURL(address, scenario, buildings) {
+    const params = this.riskParams(address, scenario, buildings);
+    params.set('lang', I18n.lang);
+    params.set('save', 'true');
+    return `/api/report.pdf?${params}`;
+  },
+
+  // permalink is the shareable URL of an assessment. It leaves out the
+  // language so that each reader gets their own.
+  permalink(address, scenario, buildings) {
+    return `/?${this.riskParams(address, scenario, buildings)}`;
+  },
+
+  // parsePermalink reads an address and options from a permalink's query
+  // string, or returns null when there is none.
+  parsePermalink(search) {
+    const params = new URLSearchParams(search);
+    if (!params.has('lat') || !params.has('lon') || !params.has('knr')) return null;
+    return {
+      address: {
+        latitude: Number(params.get('lat')),
+        longitude: Number(params.get('lon')),
+        kommunenummer: params.get('knr'),
+        text: params.get('text') || '',
+        kommunenavn: params.get('kommune') || '',
+      },
+      scenario: params.get('scenario') || 'present',
+      buildings: params.get('buildings') === 'true',
+    };
+  },
+
+  riskParams(address, scenario, buildings) {
     const params = new URLSearchParams({
       lat: address.latitude,
       lon: address.longitude,
@@ -14,8 +55,8 @@ const Api = {
       text: address.text || '',
       kommune: address.kommunenavn || '',
     });
-    const resp = await fetch(`/api/risk?${params}`);
-    if (!resp.ok) throw new Error(`Risk assessment failed: ${resp.status}`);
-    return resp.json();
+    if (scenario && scenario !== 'present') params.set('scenario', scenario);
+    if (buildings) params.set('buildings', 'true');
+    return params;
   },
 };
@@ -1,24 +1,66 @@
 // app.js — Main controller wiring search, dashboard, and map.
 document.addEventListener('DOMContentLoaded', () => {
+  I18n.init();
+  I18n.apply();
   Dashboard.init();
   HazardMap.init();
 
-  Search.init(async (address) => {
+  const scenarioSelect = document.getElementById('scenario-select');
+  const buildingsToggle = document.getElementById('buildings-toggle');
+  const langSelect = document.getElementById('lang-select');
+  let currentAddress = null;
+
+  const assess = async (address) => {
     const loading = document.getElementById('loading');
     const dashboard = document.getElementById('dashboard');
 
+    currentAddress = address;
+    // Keep the address bar a shareable permalink of what is shown.
+    history.replaceState(null, '', Api.permalink(address, scenarioSelect.value, buildingsToggle.checked));
     dashboard.hidden = true;
     loading.hidden = false;
 
     try {
-      const data = await Api.risk(address);
+      const data = await Api.risk(address, scenarioSelect.value, buildingsToggle.checked);
       loading.hidden = true;
       Dashboard.render(data);
-      HazardMap.setLocation(address.latitude, address.longitude, data.historical_events || []);
+      document.getElementById('report-link').href = Api.reportURL(address, scenarioSelect.value, buildingsToggle.checked);
+      HazardMap.setLocation(address.latitude, address.longitude, data.historical_events || [], data.buildings || []);
+      HazardMap.setAlerts(data.weather_alerts || []);
     } catch (err) {
       loading.hidden = true;
       console.error('Risk assessment error:', err);
-      alert('Kunne ikke hente risikovurdering. Prøv igjen senere.');
+      alert(I18n.t('Kunne ikke hente risikovurdering. Prøv igjen senere.'));
     }
+  };
+
+  Search.init(assess);
+
+  scenarioSelect.addEventListener('change', () => {
+    if (currentAddress) assess(currentAddress);
+  });
+
+  buildingsToggle.addEventListener('change', () => {
+    if (currentAddress) assess(currentAddress);
   });
+
+  Object.entries(I18n.languages).forEach(([code, lang]) => {
+    langSelect.add(new Option(lang.name, code, false, code === I18n.lang));
+  });
+  langSelect.addEventListener('change', () => {
+    I18n.setLang(langSelect.value);
+    HazardMap.renderLayerToggles();
+    if (currentAddress) assess(currentAddress);
+  });
+
+  // Boot straight into the assessment when opened from a permalink.
+  const permalink = Api.parsePermalink(location.search);
+  if (permalink) {
+    if ([...scenarioSelect.options].some(o => o.value === permalink.scenario)) {
+      scenarioSelect.value = permalink.scenario;
+    }
+    buildingsToggle.checked = permalink.buildings;
+    document.getElementById('search-input').value = permalink.address.text;
+    assess(permalink.address);
+  }
 });
@@ -5,6 +5,13 @@ const Dashboard = {
   alertsEl: null,
   dashboardEl: null,
 
+  // Hazard card ID -> HistoricalEvent.source listed on that card.
+  historicalSources: {
+    historical_landslides: 'skredhendelser',
+    historical_floods: 'flomhendelser',
+    historical_quick_clay: 'kvikkleireskred',
+  },
+
   init() {
     this.bannerEl = document.getElementById('score-banner');
     this.cardsEl = document.getElementById('hazard-cards');
@@ -15,6 +22,7 @@ const Dashboard = {
   render(data) {
     this.renderBanner(data);
     this.renderAlerts(data.weather_alerts || []);
+    this.renderVarsom(data.varsom_warnings || []);
     this.renderCards(data.hazards || [], data.historical_events || []);
     this.dashboardEl.hidden = false;
   },
@@ -30,12 +38,52 @@ const Dashboard = {
     this.bannerEl.className = `score-banner level-${this.safeLevel(data.overall_level)}`;
     this.bannerEl.innerHTML = `
       <div class="score-number">${Number(data.overall_score) || 0}</div>
-      <div class="score-label">${levelLabels[data.overall_level] || ''}</div>
+      <div class="score-label">${levelLabels[data.overall_level] ? I18n.t(levelLabels[data.overall_level]) : ''}</div>
       <div class="score-summary">${this.esc(data.summary)}</div>
-      <div class="score-address">${this.esc(data.address.text)}${data.elevation != null ? ` (${data.elevation.toFixed(1)} moh.)` : ''}</div>
+      <div class="score-address">${this.esc(data.address.text)}${data.elevation != null ? ` (${this.elevationText(data.elevation, data.elevation_source)})` : ''}</div>
+      ${data.assessment_id ? `<div class="score-id">${I18n.t('Vurderings-ID')}: <a href="/api/assessments/${encodeURIComponent(data.assessment_id)}" target="_blank" rel="noopener">${this.esc(data.assessment_id)}</a></div>` : ''}
+      ${data.terrain || data.watercourse ? `<div class="score-terrain">${this.terrainText(data.terrain, data.watercourse)}</div>` : ''}
+      ${data.current_risk && data.current_risk.escalations && data.current_risk.escalations.length ? `<div class="score-current">${I18n.t('Risiko nå')}: ${Number(data.current_risk.score) || 0} (${this.levelText(data.current_risk.level)}) &mdash; ${this.esc(data.current_risk.summary)}</div>` : ''}
+      ${(data.buildings || []).map(b => `<div class="score-building">${this.buildingText(b)}</div>`).join('')}
+      ${data.buildings_error ? `<div class="score-building">${this.esc(data.buildings_error)}</div>` : ''}
+      ${data.projection ? `<div class="score-projection">${I18n.t('Klimascenario')} ${this.esc(data.projection.scenario)}: ${Number(data.projection.overall_score) || 0} (${this.levelText(data.projection.overall_level)})</div>
+        ${data.projection.note ? `<div class="score-projection-note">${this.esc(data.projection.note)}</div>` : ''}` : ''}
     `;
   },
 
+  buildingText(b) {
+    let text = `${b.id ? I18n.t('Bygning {id}', { id: this.esc(b.id) }) : I18n.t('Bygningen')}: ${I18n.t('verste punkt')} ${Number(b.overall_score) || 0} (${this.levelText(b.overall_level)})`;
+    if (b.lowest_elevation != null) text += `, ${I18n.t('laveste hjørne {z} moh.', { z: Number(b.lowest_elevation).toFixed(1) })}`;
+    return text;
+  },
+
+  elevationText(elevation, src) {
+    let text = `${elevation.toFixed(1)} ${I18n.t('moh.')}`;
+    if (src) {
+      text += ` ±${Number(src.uncertainty_m).toFixed(1)} m, ${this.esc(src.datum)}`;
+      if (src.source) text += `, ${this.esc(src.source.toUpperCase())}`;
+    }
+    return text;
+  },
+
+  // terrainText describes the terrain and the nearest water; either may be
+  // missing.
+  terrainText(t, w) {
+    const parts = [];
+    if (t) {
+      const deg = Number(t.slope_deg).toFixed(0);
+      parts.push(t.aspect ? I18n.t('Helning {deg}° mot {aspect}', { deg, aspect: this.esc(t.aspect) }) : I18n.t('Helning {deg}°', { deg }));
+      if (t.max_rise_m > 0) parts.push(I18n.t('terrenget stiger {m} m innen 100 m', { m: Number(t.max_rise_m).toFixed(0) }));
+    }
+    if (w) {
+      const name = w.name ? this.esc(w.name) : I18n.t(w.kind === 'lake' ? 'nærmeste innsjø' : 'nærmeste elv');
+      let text = I18n.t('{d} m til {name}', { d: Number(w.distance_m) || 0, name });
+      if (w.height_above_m != null) text += ` (${I18n.t('{m} m over', { m: Number(w.height_above_m).toFixed(1) })})`;
+      parts.push(text);
+    }
+    return parts.join(' &middot; ');
+  },
+
   renderAlerts(alerts) {
     this.alertsEl.innerHTML = '';
     if (alerts.length === 0) return;
@@ -46,8 +94,37 @@ const Dashboard = {
       const safeSeverity = allowedSeverity.includes(alert.severity) ? alert.severity : 'Minor';
       div.className = `alert-card severity-${safeSeverity}`;
       div.innerHTML = `
-        <div class="alert-event">${this.esc(alert.event)} — ${this.esc(alert.severity)}</div>
+        <div class="alert-event">${this.esc(alert.event)} — ${this.esc(alert.severity)}${alert.onset ? ` &middot; ${this.alertPeriod(alert)}` : ''}</div>
         <div class="alert-desc">${this.esc(alert.description)}</div>
+        ${alert.consequences ? `<div class="alert-desc">${this.esc(alert.consequences)}</div>` : ''}
+      `;
+      this.alertsEl.appendChild(div);
+    });
+  },
+
+  alertPeriod(alert) {
+    const fmt = s => new Date(s).toLocaleString(I18n.locale(), { weekday: 'short', day: 'numeric', month: 'short', hour: '2-digit', minute: '2-digit' });
+    const upcoming = new Date(alert.onset) > new Date();
+    const period = `${fmt(alert.onset)}–${alert.expires ? fmt(alert.expires) : ''}`;
+    return this.esc(upcoming ? I18n.t('Kommende: {period}', { period }) : period);
+  },
+
+  renderVarsom(warnings) {
+    const typeLabels = { flood: 'Flomvarsel', landslide: 'Jordskredvarsel', avalanche: 'Snøskredvarsel' };
+    // Show only yellow and above; green days are the normal state.
+    const active = warnings.filter(w => (w.type === 'avalanche' ? w.level >= 3 : w.level >= 2));
+    active.sort((a, b) => (a.valid_from || '').localeCompare(b.valid_from || ''));
+
+    active.forEach(w => {
+      const level = Number(w.level) || 0;
+      const step = w.type === 'avalanche' ? level - 1 : level;
+      const severity = step >= 4 ? 'Extreme' : step === 3 ? 'Severe' : 'Moderate';
+      const day = (w.valid_from || '').substring(0, 10);
+      const div = document.createElement('div');
+      div.className = `alert-card severity-${severity}`;
+      div.innerHTML = `
+        <div class="alert-event">${this.esc(typeLabels[w.type] ? I18n.t(typeLabels[w.type]) : w.type)} — ${I18n.t('nivå {n}', { n: level })}${w.region ? ` (${this.esc(w.region)})` : ''} &middot; ${this.esc(day)}</div>
+        <div class="alert-desc">${this.esc(w.main_text)}</div>
       `;
       this.alertsEl.appendChild(div);
     });
@@ -64,40 +141,57 @@ const Dashboard = {
     this.cardsEl.innerHTML = '';
     hazards.forEach(h => {
       const div = document.createElement('div');
-      div.className = `hazard-card level-${this.safeLevel(h.level)}`;
+      // A zero score where NVE has not mapped the area means unknown, not safe.
+      const unknown = h.coverage === 'not_mapped' && !h.score;
+      const level = unknown ? 'unknown' : this.safeLevel(h.level);
+      div.className = `hazard-card level-${level}`;
       div.innerHTML = `
-        <div class="hazard-name">${this.esc(h.name)}</div>
+        <div class="hazard-name">${this.esc(h.name)}${this.coverageBadge(h.coverage)}</div>
         ${h.error
           ? `<div class="hazard-error">${this.esc(h.error)}</div>`
           : `
-            <div class="hazard-score">${Number(h.score) || 0}</div>
-            <div class="hazard-level">${this.levelText(h.level)}</div>
+            <div class="hazard-score">${unknown ? '?' : Number(h.score) || 0}</div>
+            <div class="hazard-level">${this.levelText(level)}</div>
             <div class="hazard-details">${this.esc(h.details || h.description)}</div>
+            ${h.storm_surge_scenarios ? `
+              <table class="surge-table">
+                <tr><th>Scenario</th><th>${I18n.t('År')}</th><th>${I18n.t('Bygninger')}</th></tr>
+                ${h.storm_surge_scenarios.map(s => `<tr><td>${this.esc(s.code)}</td><td>${this.esc(s.year)}</td><td>${Number(s.buildings) || 0}</td></tr>`).join('')}
+              </table>` : ''}
+            ${h.projected ? `
+              <div class="hazard-projection level-${this.safeLevel(h.projected.level)}">
+                <span class="projection-score">${this.esc(h.projected.scenario)}: ${Number(h.projected.score) || 0}</span>
+                <span class="projection-desc">${this.esc(h.projected.details || h.projected.description)}</span>
+              </div>` : ''}
           `}
       `;
 
-      // Add mini event list for historical landslides card
-      if (h.id === 'historical_landslides' && !h.error && historicalEvents.length > 0) {
+      // Add mini event list for the historical event cards
+      const cardEvents = historicalEvents.filter(e => e.source === this.historicalSources[h.id]);
+      if (!h.error && cardEvents.length > 0) {
         const list = document.createElement('div');
         list.className = 'event-list';
-        const shown = historicalEvents.slice(0, 5);
+        const shown = cardEvents.slice(0, 5);
         shown.forEach(e => {
           const item = document.createElement('div');
           item.className = 'event-item';
           const tags = [];
-          if (e.building_damage) tags.push('bygning');
-          if (e.road_damage) tags.push('veg');
-          if (e.fatalities > 0) tags.push(`${Number(e.fatalities)} omkommet`);
+          if (e.building_damage) tags.push(I18n.t('bygning'));
+          if (e.road_damage) tags.push(I18n.t('veg'));
+          if (e.fatalities > 0) tags.push(I18n.t('{n} omkommet', { n: Number(e.fatalities) }));
+          if (e.position === 'upslope') tags.push(I18n.t('ovenfor'));
+          if (e.position === 'downslope') tags.push(I18n.t('nedenfor'));
+          if (e.contribution > 0) tags.push(I18n.t('+{n} poeng', { n: Number(e.contribution) }));
           item.innerHTML = `
-            <span class="event-type">${this.esc(e.type)}</span>
-            <span class="event-meta">${e.date ? this.esc(e.date) : 'ukjent dato'} &middot; ${Number(e.distance_m) || 0} m${tags.length ? ' &middot; ' + this.esc(tags.join(', ')) : ''}</span>
+            <span class="event-type">${this.esc(I18n.t(e.type))}</span>
+            <span class="event-meta">${e.date ? this.esc(e.date) : I18n.t('ukjent dato')} &middot; ${Number(e.distance_m) || 0} m${tags.length ? ' &middot; ' + this.esc(tags.join(', ')) : ''}</span>
           `;
           list.appendChild(item);
         });
-        if (historicalEvents.length > 5) {
+        if (cardEvents.length > 5) {
           const more = document.createElement('div');
           more.className = 'event-item event-more';
-          more.textContent = `+ ${historicalEvents.length - 5} flere hendelser`;
+          more.textContent = I18n.t('+ {n} flere hendelser', { n: cardEvents.length - 5 });
           list.appendChild(more);
         }
         div.appendChild(list);
@@ -107,6 +201,12 @@ const Dashboard = {
     });
   },
 
+  coverageBadge(coverage) {
+    const labels = { not_mapped: 'Ikke kartlagt', awareness_only: 'Aktsomhetskart', estimated: 'Estimat' };
+    if (!labels[coverage]) return '';
+    return ` <span class="coverage-badge coverage-${coverage}">${I18n.t(labels[coverage])}</span>`;
+  },
+
   safeLevel(level) {
     const allowed = ['low', 'medium', 'high', 'very_high', 'unknown'];
     return allowed.includes(level) ? level : 'unknown';
@@ -114,7 +214,7 @@ const Dashboard = {
 
   levelText(level) {
     const m = { low: 'Lav', medium: 'Moderat', high: 'Høy', very_high: 'Svært høy', unknown: 'Ukjent' };
-    return m[level] || level;
+    return m[level] ? I18n.t(m[level]) : level;
   },
 
   esc(str) {
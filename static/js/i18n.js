@@ -0,0 +1,207 @@
+// i18n.js — UI translations. Bokmål texts are the keys; missing
+// translations fall back to Bokmål. The chosen language is also sent to the
+// API so generated texts match.
+const I18n = {
+  lang: 'nb',
+
+  languages: {
+    nb: { name: 'Bokmål', locale: 'nb-NO' },
+    nn: { name: 'Nynorsk', locale: 'nn-NO' },
+    en: { name: 'English', locale: 'en-GB' },
+  },
+
+  strings: {
+    nn: {
+      'Hvor trygt bor du?': 'Kor trygt bur du?',
+      'Sjekk naturfare for din adresse': 'Sjekk naturfare for adressa di',
+      'Skriv inn en adresse...': 'Skriv inn ei adresse...',
+      'Vurder hele bygningen': 'Vurder heile bygningen',
+      'Henter risikodata...': 'Hentar risikodata...',
+      'Data fra': 'Data frå',
+      'Kun veiledende — erstatter ikke profesjonell vurdering.': 'Berre rettleiande — erstattar ikkje profesjonell vurdering.',
+      'Kunne ikke hente risikovurdering. Prøv igjen senere.': 'Kunne ikkje hente risikovurdering. Prøv igjen seinare.',
+      'Lav risiko': 'Låg risiko',
+      'Høy risiko': 'Høg risiko',
+      'Svært høy risiko': 'Svært høg risiko',
+      'Lav': 'Låg',
+      'Høy': 'Høg',
+      'Svært høy': 'Svært høg',
+      'Ukjent': 'Ukjend',
+      'Risiko nå': 'Risiko no',
+      'laveste hjørne {z} moh.': 'lågaste hjørne {z} moh.',
+      'Helning {deg}°': 'Helling {deg}°',
+      'Helning {deg}° mot {aspect}': 'Helling {deg}° mot {aspect}',
+      'terrenget stiger {m} m innen 100 m': 'terrenget stig {m} m innan 100 m',
+      'nærmeste innsjø': 'nærmaste innsjø',
+      'nærmeste elv': 'nærmaste elv',
+      'Kommende: {period}': 'Kommande: {period}',
+      'Flomvarsel': 'Flaumvarsel',
+      'Bygninger': 'Bygningar',
+      '{n} omkommet': '{n} omkomne',
+      'ovenfor': 'ovanfor',
+      'nedenfor': 'nedanfor',
+      'ukjent dato': 'ukjend dato',
+      '+ {n} flere hendelser': '+ {n} fleire hendingar',
+      'Ikke kartlagt': 'Ikkje kartlagt',
+      'Aktsomhetskart': 'Aktsemdkart',
+      'Flomsoner': 'Flaumsoner',
+      'Flomaktsomhet': 'Flaumaktsemd',
+      'Skredaktsomhet': 'Skredaktsemd',
+      '{d} m fra adressen': '{d} m frå adressa',
+      '{m} m høydeforskjell': '{m} m høgdeskilnad',
+      // Event types
+      'Skred fra fast fjell': 'Skred frå fast fjell',
+      'Undervannsskred': 'Undervassskred',
+      'Løssnøskred': 'Laussnøskred',
+      'Vått løssnøskred': 'Vått laussnøskred',
+      'Tørt løssnøskred': 'Tørt laussnøskred',
+      'Løsmasseskred': 'Lausmasseskred',
+      'Flomskred': 'Flaumskred',
+      'Utglidning': 'Utgliding',
+      'Skred (type ikke angitt)': 'Skred (type ikkje oppgitt)',
+      'Skred (ukjent type)': 'Skred (ukjend type)',
+      'Flom': 'Flaum',
+    },
+    en: {
+      'Hvor trygt bor du?': 'How safe is your home?',
+      'Sjekk naturfare for din adresse': 'Check natural hazards for your address',
+      'Skriv inn en adresse...': 'Enter an address...',
+      'Søk etter adresse': 'Search for an address',
+      'Klimascenario': 'Climate scenario',
+      'I dag': 'Today',
+      'Vurder hele bygningen': 'Assess the whole building',
+      'Henter risikodata...': 'Fetching risk data...',
+      'Farekart': 'Hazard map',
+      'Data fra': 'Data from',
+      'og': 'and',
+      'Kun veiledende — erstatter ikke profesjonell vurdering.': 'For guidance only — does not replace a professional assessment.',
+      'Kunne ikke hente risikovurdering. Prøv igjen senere.': 'Could not fetch the risk assessment. Please try again later.',
+      'Lav risiko': 'Low risk',
+      'Moderat risiko': 'Moderate risk',
+      'Høy risiko': 'High risk',
+      'Svært høy risiko': 'Very high risk',
+      'Lav': 'Low',
+      'Moderat': 'Moderate',
+      'Høy': 'High',
+      'Svært høy': 'Very high',
+      'Ukjent': 'Unknown',
+      'Risiko nå': 'Risk now',
+      'Bygning {id}': 'Building {id}',
+      'Bygningen': 'The building',
+      'Bygning': 'Building',
+      'verste punkt': 'worst point',
+      'laveste hjørne {z} moh.': 'lowest corner {z} m above sea level',
+      'moh.': 'm above sea level',
+      'Helning {deg}°': 'Slope {deg}°',
+      'Helning {deg}° mot {aspect}': 'Slope {deg}° facing {aspect}',
+      'terrenget stiger {m} m innen 100 m': 'terrain rises {m} m within 100 m',
+      '{d} m til {name}': '{d} m to {name}',
+      'nærmeste innsjø': 'the nearest lake',
+      'nærmeste elv': 'the nearest river',
+      '{m} m over': '{m} m above',
+      'Kommende: {period}': 'Upcoming: {period}',
+      'Flomvarsel': 'Flood warning',
+      'Jordskredvarsel': 'Landslide warning',
+      'Snøskredvarsel': 'Avalanche warning',
+      'nivå {n}': 'level {n}',
+      'År': 'Year',
+      'Bygninger': 'Buildings',
+      'bygning': 'building',
+      'veg': 'road',
+      '{n} omkommet': '{n} killed',
+      'ovenfor': 'upslope',
+      'nedenfor': 'downslope',
+      '+{n} poeng': '+{n} points',
+      'ukjent dato': 'unknown date',
+      '+ {n} flere hendelser': '+ {n} more events',
+      'Ikke kartlagt': 'Not mapped',
+      'Aktsomhetskart': 'Awareness map',
+      'Estimat': 'Estimate',
+      'Flomsoner': 'Flood zones',
+      'Flomaktsomhet': 'Flood awareness',
+      'Skredaktsomhet': 'Landslide awareness',
+      'Kvikkleire': 'Quick clay',
+      'Snøskred': 'Snow avalanche',
+      'Steinsprang': 'Rock fall',
+      'Skredfaresoner': 'Landslide hazard zones',
+      'Senterpunkt': 'Centre point',
+      'Hjørne': 'Corner',
+      'Verste punkt: {n}': 'Worst point: {n}',
+      '{d} m fra adressen': '{d} m from the address',
+      'Bygningsskade': 'Building damage',
+      'Vegskade': 'Road damage',
+      '{m} m høydeforskjell': '{m} m elevation difference',
+      'Bidrag til score: {n}': 'Contribution to score: {n}',
+      'Last ned PDF-rapport': 'Download PDF report',
+      'Vurderings-ID': 'Assessment ID',
+      // Event types
+      'Skred fra fast fjell': 'Bedrock slide',
+      'Steinskred': 'Rock slide',
+      'Fjellskred': 'Rock avalanche',
+      'Undervannsskred': 'Submarine slide',
+      'Vått snøskred': 'Wet snow avalanche',
+      'Tørt snøskred': 'Dry snow avalanche',
+      'Sørpeskred': 'Slush flow',
+      'Løssnøskred': 'Loose snow avalanche',
+      'Vått løssnøskred': 'Wet loose snow avalanche',
+      'Tørt løssnøskred': 'Dry loose snow avalanche',
+      'Flakskred': 'Slab avalanche',
+      'Vått flakskred': 'Wet slab avalanche',
+      'Tørt flakskred': 'Dry slab avalanche',
+      'Løsmasseskred': 'Soil slide',
+      'Kvikkleireskred': 'Quick clay slide',
+      'Flomskred': 'Debris flow',
+      'Leirskred': 'Clay slide',
+      'Jordskred': 'Debris slide',
+      'Isnedfall': 'Ice fall',
+      'Skavlfall': 'Cornice fall',
+      'Utglidning': 'Slump',
+      'Skred (type ikke angitt)': 'Landslide (type not given)',
+      'Skred (ukjent type)': 'Landslide (unknown type)',
+      'Flom': 'Flood',
+    },
+  },
+
+  // init picks the saved language, then the browser's, defaulting to Bokmål.
+  init() {
+    const saved = localStorage.getItem('lang');
+    const browser = (navigator.language || '').toLowerCase().split('-')[0];
+    const aliases = { no: 'nb' };
+    const candidate = saved || aliases[browser] || browser;
+    this.lang = this.languages[candidate] ? candidate : 'nb';
+  },
+
+  setLang(lang) {
+    if (!this.languages[lang]) return;
+    this.lang = lang;
+    localStorage.setItem('lang', lang);
+    this.apply();
+  },
+
+  locale() {
+    return this.languages[this.lang].locale;
+  },
+
+  // t translates a Bokmål text and fills in {name} placeholders.
+  t(key, vars) {
+    const table = this.strings[this.lang] || {};
+    const text = table[key] !== undefined ? table[key] : key;
+    return text.replace(/\{(\w+)\}/g, (m, name) => (vars && vars[name] !== undefined ? vars[name] : m));
+  },
+
+  // apply translates static markup: data-i18n sets the text, and
+  // data-i18n-placeholder and data-i18n-aria-label set those attributes.
+  apply() {
+    document.documentElement.lang = this.lang;
+    document.title = this.t('Hvor trygt bor du?');
+    document.querySelectorAll('[data-i18n]').forEach(el => {
+      el.textContent = this.t(el.dataset.i18n);
+    });
+    document.querySelectorAll('[data-i18n-placeholder]').forEach(el => {
+      el.placeholder = this.t(el.dataset.i18nPlaceholder);
+    });
+    document.querySelectorAll('[data-i18n-aria-label]').forEach(el => {
+      el.setAttribute('aria-label', this.t(el.dataset.i18nAriaLabel));
+    });
+  },
+};
@@ -3,6 +3,8 @@ const HazardMap = {
   map: null,
   marker: null,
   eventMarkers: null,
+  buildingLayers: null,
+  alertLayers: null,
   wmsLayers: {},
   layerControlEl: null,
 
@@ -47,10 +49,17 @@ const HazardMap = {
       id: 'combined',
       label: 'Skredfaresoner',
       url: 'https://nve.geodataonline.no/arcgis/services/Skredfaresoner2/MapServer/WMSServer',
-      layers: 'Skredsoner_100,Skredsoner_1000',
+      layers: 'Skredsoner_100,Skredsoner_1000,Skredsoner_5000',
     },
   ],
 
+  // Marker style per HistoricalEvent.source.
+  eventStyles: {
+    skredhendelser: { color: '#d96830', damageColor: '#c0392b', stroke: '#fff', radius: 7 },
+    flomhendelser: { color: '#2e86c1', damageColor: '#1b4f72', stroke: '#fff', radius: 7 },
+    kvikkleireskred: { color: '#8e44ad', damageColor: '#5b2c6f', stroke: '#2c3e50', radius: 8, dashArray: '3' },
+  },
+
   init() {
     this.layerControlEl = document.getElementById('map-layers');
     this.map = L.map('map').setView([65, 14], 5);
@@ -68,6 +77,8 @@ const HazardMap = {
     }).addTo(this.map);
 
     this.eventMarkers = L.layerGroup().addTo(this.map);
+    this.buildingLayers = L.layerGroup().addTo(this.map);
+    this.alertLayers = L.layerGroup().addTo(this.map);
 
     // Create WMS layers (not added to map until toggled)
     this.wmsDefinitions.forEach(def => {
@@ -89,6 +100,7 @@ const HazardMap = {
       const label = document.createElement('label');
       const checkbox = document.createElement('input');
       checkbox.type = 'checkbox';
+      checkbox.checked = this.map.hasLayer(this.wmsLayers[def.id]);
       checkbox.addEventListener('change', () => {
         if (checkbox.checked) {
           this.wmsLayers[def.id].addTo(this.map);
@@ -97,12 +109,12 @@ const HazardMap = {
         }
       });
       label.appendChild(checkbox);
-      label.appendChild(document.createTextNode(def.label));
+      label.appendChild(document.createTextNode(I18n.t(def.label)));
       this.layerControlEl.appendChild(label);
     });
   },
 
-  setLocation(lat, lon, historicalEvents) {
+  setLocation(lat, lon, historicalEvents, buildings) {
     // Recalculate size after container becomes visible
     this.map.invalidateSize();
     if (this.marker) {
@@ -111,26 +123,46 @@ const HazardMap = {
     this.marker = L.marker([lat, lon]).addTo(this.map);
     this.map.setView([lat, lon], 14);
 
+    // Building footprints, colored by their worst point
+    this.buildingLayers.clearLayers();
+    const levelColors = { low: '#2d8a4e', medium: '#c09a2b', high: '#d96830', very_high: '#c0392b' };
+    (buildings || []).forEach(b => {
+      const color = levelColors[b.overall_level] || '#7f8c8d';
+      const polygon = L.polygon(b.footprint, { color, weight: 2, fillOpacity: 0.2 });
+      polygon.bindPopup(`<b>${b.id ? I18n.t('Bygning {id}', { id: this.esc(b.id) }) : I18n.t('Bygning')}</b><br>${I18n.t('Verste punkt: {n}', { n: Number(b.overall_score) || 0 })}`);
+      this.buildingLayers.addLayer(polygon);
+      (b.points || []).forEach(p => {
+        const marker = L.circleMarker([p.latitude, p.longitude], { radius: 3, color, weight: 1, fillOpacity: 0.9 });
+        const parts = [I18n.t(p.kind === 'centroid' ? 'Senterpunkt' : 'Hjørne'), `Score ${Number(p.max_score) || 0}`];
+        if (p.elevation != null) parts.push(`${Number(p.elevation).toFixed(1)} ${I18n.t('moh.')}`);
+        marker.bindPopup(parts.join('<br>'));
+        this.buildingLayers.addLayer(marker);
+      });
+    });
+
     // Clear old event markers and add new ones
     this.eventMarkers.clearLayers();
     if (historicalEvents && historicalEvents.length > 0) {
       historicalEvents.forEach(e => {
-        const color = e.building_damage ? '#c0392b' : '#d96830';
+        const style = this.eventStyles[e.source] || this.eventStyles.skredhendelser;
         const marker = L.circleMarker([e.latitude, e.longitude], {
-          radius: 7,
-          fillColor: color,
-          color: '#fff',
+          radius: style.radius,
+          fillColor: e.building_damage ? style.damageColor : style.color,
+          color: style.stroke,
           weight: 2,
+          dashArray: style.dashArray,
           fillOpacity: 0.85,
         });
 
-        const parts = [`<b>${this.esc(e.type)}</b>`];
+        const parts = [`<b>${this.esc(I18n.t(e.type))}</b>`];
         if (e.date) parts.push(this.esc(e.date));
         if (e.location) parts.push(this.esc(e.location));
-        parts.push(`${e.distance_m} m fra adressen`);
-        if (e.building_damage) parts.push('Bygningsskade');
-        if (e.road_damage) parts.push('Vegskade');
-        if (e.fatalities > 0) parts.push(`${e.fatalities} omkommet`);
+        parts.push(I18n.t('{d} m fra adressen', { d: Number(e.distance_m) || 0 }));
+        if (e.building_damage) parts.push(I18n.t('Bygningsskade'));
+        if (e.road_damage) parts.push(I18n.t('Vegskade'));
+        if (e.fatalities > 0) parts.push(I18n.t('{n} omkommet', { n: Number(e.fatalities) }));
+        if (e.elevation_diff_m != null) parts.push(I18n.t('{m} m høydeforskjell', { m: `${e.elevation_diff_m > 0 ? '+' : ''}${Number(e.elevation_diff_m).toFixed(0)}` }));
+        if (e.contribution > 0) parts.push(I18n.t('Bidrag til score: {n}', { n: Number(e.contribution) }));
         if (e.description) parts.push(`<i>${this.esc(e.description.substring(0, 200))}</i>`);
 
         marker.bindPopup(parts.join('<br>'));
@@ -139,6 +171,21 @@ const HazardMap = {
     }
   },
 
+  setAlerts(alerts) {
+    this.alertLayers.clearLayers();
+    const colors = { Extreme: '#c0392b', Severe: '#d96830', Moderate: '#c09a2b', Minor: '#2d8a4e' };
+    alerts.forEach(a => {
+      if (!a.geometry) return;
+      const color = colors[a.severity] || colors.Minor;
+      const layer = L.geoJSON(a.geometry, {
+        style: { color, weight: 1, fillOpacity: 0.1, dashArray: '4 4' },
+        interactive: true,
+      });
+      layer.bindPopup(`<b>${this.esc(a.event)}</b> — ${this.esc(a.severity)}<br>${this.esc(a.area)}`);
+      this.alertLayers.addLayer(layer);
+    });
+  },
+
   esc(str) {
     if (!str) return '';
     const div = document.createElement('div');
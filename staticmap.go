@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	topoWMSURL = "https://wms.geonorge.no/skwms1/wms.topo"
+	nveWMSURL  = "https://nve.geodataonline.no/arcgis/services/%s/MapServer/WMSServer"
+
+	mapWidth    = 720 // pixels
+	mapHeight   = 480
+	mapRadiusM  = 600 // ground distance from the address to the left and right edges
+	mapCacheTTL = 24 * time.Hour
+)
+
+// mapOverlays are the NVE hazard layers drawn over the base map, matching
+// the layers in the frontend's map.
+var mapOverlays = []struct{ service, layers string }{
+	{"FlomAktsomhet", "Flom_aktsomhetsomrade"},
+	{"SkredSnoSteinAkt", "Aktsomhetsomrade"},
+	{"KvikkleireskredAktsomhet", "KvikkleireskredAktsomhet"},
+	{"SnoskredAktsomhet", "S2_snoskred_u_skogeffekt_Aktsomhetsomrade,S3_snoskred_Aktsomhetsomrade"},
+	{"SkredSteinAktR", "Utlopsomrade,Utlosningsomrade,Steinsprang-AktsomhetOmrader"},
+	{"Flomsoner1", "Flomsone_10arsflom,Flomsone_20arsflom,Flomsone_50arsflom,Flomsone_100arsflom,Flomsone_200arsflom"},
+	{"Skredfaresoner2", "Skredsoner_100,Skredsoner_1000,Skredsoner_5000"},
+}
+
+// hazardMap renders Kartverket's topographic map around a point with NVE's
+// hazard zones on top and the address marked. Layers that fail are left
+// out; it returns an error only when none could be fetched.
+func hazardMap(ctx context.Context, cache *Cache, lat, lon float64) (*image.RGBA, error) {
+	// Web Mercator stretches distances by 1/cos(lat).
+	x, y := webMercator(lat, lon)
+	halfW := mapRadiusM / math.Cos(lat*math.Pi/180)
+	halfH := halfW * mapHeight / mapWidth
+	bbox := fmt.Sprintf("%.1f,%.1f,%.1f,%.1f", x-halfW, y-halfH, x+halfW, y+halfH)
+
+	urls := []string{wmsGetMap(topoWMSURL, "topo", bbox)}
+	for _, o := range mapOverlays {
+		urls = append(urls, wmsGetMap(fmt.Sprintf(nveWMSURL, o.service), o.layers, bbox))
+	}
+
+	layers := make([]image.Image, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// WMS servers report errors as XML with status 200, so a fresh
+			// response is decoded before it is cached. A cached one was
+			// valid and is decoded here.
+			var img image.Image
+			data, err := cachedGetValid(ctx, cache, u, mapCacheTTL, func(body []byte) (err error) {
+				img, err = png.Decode(bytes.NewReader(body))
+				return err
+			})
+			if err == nil && img == nil {
+				img, err = png.Decode(bytes.NewReader(data))
+			}
+			if err == nil {
+				layers[i] = img
+			}
+			if err != nil {
+				log.Printf("map layer error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	dst := image.NewRGBA(image.Rect(0, 0, mapWidth, mapHeight))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	drawn := 0
+	for i, layer := range layers {
+		if layer == nil {
+			continue
+		}
+		// Overlays are half transparent so the base map shows through.
+		mask := image.NewUniform(color.Alpha{A: 0xff})
+		if i > 0 {
+			mask = image.NewUniform(color.Alpha{A: 0x99})
+		}
+		draw.DrawMask(dst, dst.Bounds(), layer, layer.Bounds().Min, mask, image.Point{}, draw.Over)
+		drawn++
+	}
+	if drawn == 0 {
+		return nil, errors.New("no map layers available")
+	}
+
+	drawMarker(dst, mapWidth/2, mapHeight/2)
+	return dst, nil
+}
+
+func wmsGetMap(base, layers, bbox string) string {
+	params := url.Values{
+		"service":     {"WMS"},
+		"version":     {"1.3.0"},
+		"request":     {"GetMap"},
+		"layers":      {layers},
+		"styles":      {""},
+		"crs":         {"EPSG:3857"},
+		"bbox":        {bbox},
+		"width":       {fmt.Sprint(mapWidth)},
+		"height":      {fmt.Sprint(mapHeight)},
+		"format":      {"image/png"},
+		"transparent": {"true"},
+	}
+	return base + "?" + params.Encode()
+}
+
+// webMercator projects a WGS84 coordinate to EPSG:3857 metres.
+func webMercator(lat, lon float64) (float64, float64) {
+	const r = 6378137.0
+	x := r * lon * math.Pi / 180
+	y := r * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return x, y
+}
+
+// drawMarker draws a red dot with a white ring.
+func drawMarker(img *image.RGBA, cx, cy int) {
+	const outer, inner = 9, 6
+	red := color.RGBA{R: 0xc0, G: 0x39, B: 0x2b, A: 0xff}
+	for dy := -outer; dy <= outer; dy++ {
+		for dx := -outer; dx <= outer; dx++ {
+			switch d := dx*dx + dy*dy; {
+			case d <= inner*inner:
+				img.Set(cx+dx, cy+dy, red)
+			case d <= outer*outer:
+				img.Set(cx+dx, cy+dy, color.White)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var assessmentIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// errAssessmentNotFound is returned for an unknown assessment ID.
+var errAssessmentNotFound = errors.New("assessment not found")
+
+const storeFile = "assessments.db"
+
+var assessmentsBucket = []byte("assessments")
+
+// Store keeps assessment snapshots in an embedded bbolt database, keyed by
+// ID. Snapshots are written once and never changed, so a stored assessment
+// can be cited later even if the underlying maps change.
+type Store struct {
+	db *bolt.DB
+}
+
+// OpenStore opens the store in dir, creating the directory and database if
+// needed. The database is locked while open, so a second server on the same
+// directory fails instead of waiting.
+func OpenStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating store directory: %w", err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, storeFile), 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(assessmentsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save assigns the snapshot a new ID and writes it. The write is synced to
+// disk before Save returns.
+func (s *Store) Save(snap *AssessmentSnapshot) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("generating assessment id: %w", err)
+	}
+	snap.ID = hex.EncodeToString(id)
+	snap.Response.AssessmentID = snap.ID
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		return fmt.Errorf("encoding assessment: %w", err)
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(assessmentsBucket)
+		if b.Get([]byte(snap.ID)) != nil {
+			return fmt.Errorf("assessment id %s already used", snap.ID)
+		}
+		return b.Put([]byte(snap.ID), buf.Bytes())
+	})
+	if err != nil {
+		return fmt.Errorf("writing assessment: %w", err)
+	}
+	return nil
+}
+
+// Load returns the stored snapshot as written.
+func (s *Store) Load(id string) ([]byte, error) {
+	if !assessmentIDPattern.MatchString(id) {
+		return nil, errAssessmentNotFound
+	}
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid within the transaction.
+		data = bytes.Clone(tx.Bucket(assessmentsBucket).Get([]byte(id)))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading assessment: %w", err)
+	}
+	if data == nil {
+		return nil, errAssessmentNotFound
+	}
+	return data, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap := AssessmentSnapshot{
+		CreatedAt: "2026-10-18T12:00:00Z",
+		Request:   "lat=59.9&lon=10.7&lang=nb",
+		Upstream:  []UpstreamResponse{{URL: "https://example.invalid/x", Status: 503, Error: codeUpstreamUnavailable}},
+	}
+	if err := store.Save(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if !assessmentIDPattern.MatchString(snap.ID) || snap.Response.AssessmentID != snap.ID {
+		t.Fatalf("id %q, response id %q", snap.ID, snap.Response.AssessmentID)
+	}
+	data, err := store.Load(snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got AssessmentSnapshot
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != snap.ID || got.Request != snap.Request || len(got.Upstream) != 1 || got.Upstream[0].Error != codeUpstreamUnavailable {
+		t.Errorf("loaded %+v", got)
+	}
+
+	for _, id := range []string{"0123456789abcdef0123456789abcdef", "../../etc/passwd", ""} {
+		if _, err := store.Load(id); !errors.Is(err, errAssessmentNotFound) {
+			t.Errorf("Load(%q): %v, want not found", id, err)
+		}
+	}
+
+	// Snapshots survive a restart byte for byte.
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	again, err := store.Load(snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Error("snapshot changed after reopening")
+	}
+}
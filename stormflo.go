@@ -19,15 +19,19 @@ type stormfloEntry struct {
 }
 
 // getStormSurge fetches storm surge consequence data for a municipality.
-// Returns the full list of scenario entries (e.g. 20y, 200y, 1000y).
+// Returns the full list of scenario entries (e.g. 20y, 200y, 1000y), or none
+// for municipalities without data.
 func getStormSurge(ctx context.Context, cache *Cache, kommunenummer string) ([]stormfloEntry, error) {
 	u := fmt.Sprintf("%s/%s.json", stormfloBaseURL, kommunenummer)
 
 	data, err := cachedGet(ctx, cache, u, stormfloCacheTTL)
-	if err != nil {
+	if isNotFound(err) {
 		// Many inland municipalities return 404 — not an error.
 		return nil, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("stormflo: %w", err)
+	}
 
 	var entries []stormfloEntry
 	if err := json.Unmarshal(data, &entries); err != nil {
@@ -36,3 +40,52 @@ func getStormSurge(ctx context.Context, cache *Cache, kommunenummer string) ([]s
 
 	return entries, nil
 }
+
+// stormSurgeExposure summarizes a municipality's storm surge consequence data
+// for scoring an address in it.
+type stormSurgeExposure struct {
+	HasData bool
+
+	// Factor scales elevation-based scores by how much of the municipality
+	// is affected; 1 when the share is unknown.
+	Factor float64
+}
+
+// scale applies the exposure factor to an elevation-based score.
+func (e stormSurgeExposure) scale(score int) int {
+	return min(100, int(float64(score)*e.Factor+0.5))
+}
+
+// stormSurgeShareFactors map the share of a municipality's buildings affected
+// in its worst scenario to a score factor, checked in order. Address count
+// stands in for the number of buildings.
+var stormSurgeShareFactors = []struct {
+	minShare float64
+	factor   float64
+}{
+	{0.02, 1.25},
+	{0.005, 1.0},
+	{0, 0.75},
+}
+
+// worstStormSurgeScenario returns the scenario affecting the most buildings.
+func worstStormSurgeScenario(scenarios []StormSurgeScenario) StormSurgeScenario {
+	var worst StormSurgeScenario
+	for _, s := range scenarios {
+		if s.Buildings > worst.Buildings {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// stormSurgeShareFactor returns the score factor for the share of addresses
+// affected.
+func stormSurgeShareFactor(share float64) float64 {
+	for _, f := range stormSurgeShareFactors {
+		if share >= f.minShare {
+			return f.factor
+		}
+	}
+	return 1
+}
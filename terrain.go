@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NVE Elvenett (rivers as polylines) and Innsjødatabase (lakes as polygons).
+const (
+	elvenettURL = "https://nve.geodataonline.no/arcgis/rest/services/Elvenett1/MapServer/2/query"
+	innsjoURL   = "https://nve.geodataonline.no/arcgis/rest/services/Innsjodatabase2/MapServer/5/query"
+)
+
+// waterLayer is an NVE layer of water bodies searched for the nearest one.
+type waterLayer struct {
+	Kind       string // Watercourse.Kind
+	URL        string
+	NameFields []string
+}
+
+var waterLayers = []waterLayer{
+	{Kind: "river", URL: elvenettURL, NameFields: []string{"elvenavn", "elvNavn", "navn"}},
+	{Kind: "lake", URL: innsjoURL, NameFields: []string{"navn"}},
+}
+
+// Terrain sampling around the address. The 3x3 grid gives local slope and
+// aspect (Horn's method); the rings show steep ground and rising terrain
+// nearby, which drive landslide and rock fall exposure.
+const (
+	terrainGridSpacingM = 10.0
+	terrainRingPoints   = 8
+	watercourseSearchM  = 500
+
+	// watercourseGeneralizeM simplifies river and lake geometry on the
+	// server: large lakes have shorelines of hundreds of thousands of
+	// vertices, which would exceed cachedGet's size limit.
+	watercourseGeneralizeM = 5
+
+	// Thresholds for adjusting slope-driven hazards, measured within the
+	// outermost ring.
+	flatSlopeDeg      = 10.0
+	flatRiseM         = 10.0
+	steepSlopeDeg     = 30.0
+	steepRiseM        = 30.0
+	terrainFlatFactor = 0.6
+	terrainSteepBonus = 10
+
+	metersPerDegreeLat = 111_320.0
+)
+
+// terrainRings are the radii in meters of the sample rings around the address.
+var terrainRings = []float64{50, 100}
+
+// compassPoints names aspect octants, starting at north.
+var compassPoints = []string{"N", "NØ", "Ø", "SØ", "S", "SV", "V", "NV"}
+
+// localFrame converts between lat/lon and meters east/north of an origin.
+// An equirectangular approximation is accurate to well under a meter at the
+// distances used here.
+type localFrame struct {
+	lat0, lon0, cosLat float64
+}
+
+func newLocalFrame(lat, lon float64) localFrame {
+	return localFrame{lat0: lat, lon0: lon, cosLat: math.Cos(lat * math.Pi / 180)}
+}
+
+func (f localFrame) toLatLon(east, north float64) (float64, float64) {
+	return f.lat0 + north/metersPerDegreeLat, f.lon0 + east/(metersPerDegreeLat*f.cosLat)
+}
+
+func (f localFrame) toMeters(lat, lon float64) (float64, float64) {
+	return (lon - f.lon0) * metersPerDegreeLat * f.cosLat, (lat - f.lat0) * metersPerDegreeLat
+}
+
+// sampleTerrain fetches a 3x3 grid and rings of points in one batch and
+// derives slope, aspect and the surrounding relief.
+func sampleTerrain(ctx context.Context, cache *Cache, lat, lon float64) (*Terrain, error) {
+	frame := newLocalFrame(lat, lon)
+
+	// Grid rows north to south, columns west to east; index 4 is the address.
+	var offsets [][2]float64
+	for _, dn := range []float64{1, 0, -1} {
+		for _, de := range []float64{-1, 0, 1} {
+			offsets = append(offsets, [2]float64{de * terrainGridSpacingM, dn * terrainGridSpacingM})
+		}
+	}
+	for _, r := range terrainRings {
+		for i := range terrainRingPoints {
+			angle := 2 * math.Pi * float64(i) / terrainRingPoints
+			offsets = append(offsets, [2]float64{r * math.Sin(angle), r * math.Cos(angle)})
+		}
+	}
+
+	points := make([][2]float64, len(offsets))
+	for i, o := range offsets {
+		pLat, pLon := frame.toLatLon(o[0], o[1])
+		points[i] = [2]float64{pLat, pLon}
+	}
+
+	z, err := getElevations(ctx, cache, points)
+	if err != nil {
+		return nil, fmt.Errorf("terrain: %w", err)
+	}
+	for i := range 9 {
+		if z[i] == nil {
+			return nil, fmt.Errorf("terrain: missing elevation in grid")
+		}
+	}
+
+	g := func(i int) float64 { return *z[i] }
+	d := terrainGridSpacingM
+	dzdx := ((g(2) + 2*g(5) + g(8)) - (g(0) + 2*g(3) + g(6))) / (8 * d)
+	dzdy := ((g(0) + 2*g(1) + g(2)) - (g(6) + 2*g(7) + g(8))) / (8 * d)
+
+	t := &Terrain{
+		SlopeDeg: round1(math.Atan(math.Hypot(dzdx, dzdy)) * 180 / math.Pi),
+	}
+	if t.SlopeDeg >= 1 {
+		// Aspect is the compass direction the slope faces (downhill).
+		aspect := math.Mod(math.Atan2(-dzdx, -dzdy)*180/math.Pi+360, 360)
+		aspect = round1(aspect)
+		t.AspectDeg = &aspect
+		t.Aspect = compassPoints[int(math.Mod(aspect+22.5, 360)/45)]
+	}
+
+	center := g(4)
+	t.MaxSlopeDeg = t.SlopeDeg
+	for i := 9; i < len(offsets); i++ {
+		if z[i] == nil {
+			continue
+		}
+		dist := math.Hypot(offsets[i][0], offsets[i][1])
+		rise := *z[i] - center
+		if rise > t.MaxRiseM {
+			t.MaxRiseM = round1(rise)
+		}
+		if s := math.Atan(math.Abs(rise)/dist) * 180 / math.Pi; s > t.MaxSlopeDeg {
+			t.MaxSlopeDeg = round1(s)
+		}
+	}
+
+	return t, nil
+}
+
+type waterResponse struct {
+	Features []waterFeature `json:"features"`
+}
+
+// waterFeature holds a river polyline (paths) or a lake polygon (rings).
+type waterFeature struct {
+	Attributes map[string]any `json:"attributes"`
+	Geometry   *struct {
+		Paths [][][2]float64 `json:"paths"`
+		Rings [][][2]float64 `json:"rings"`
+	} `json:"geometry"`
+}
+
+// nearestWatercourse finds the closest river or lake shore within
+// watercourseSearchM and the address's height above its water surface,
+// taken as the terrain height at the nearest point. It returns nil, nil when
+// there is no water nearby, and an error if any layer lookup failed.
+func nearestWatercourse(ctx context.Context, cache *Cache, lat, lon float64, elevation *float64) (*Watercourse, error) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		best  *Watercourse
+		bestE float64
+		bestN float64
+		errs  []error
+		frame = newLocalFrame(lat, lon)
+	)
+	for _, layer := range waterLayers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, e, n, err := nearestInLayer(ctx, cache, layer, frame, lat, lon)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			if w != nil && (best == nil || w.DistanceM < best.DistanceM) {
+				best, bestE, bestN = w, e, n
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	if elevation != nil {
+		wLat, wLon := frame.toLatLon(bestE, bestN)
+		z, err := getElevations(ctx, cache, [][2]float64{{wLat, wLon}})
+		if err != nil {
+			return nil, fmt.Errorf("watercourse elevation: %w", err)
+		}
+		if len(z) == 1 && z[0] != nil {
+			h := round1(*elevation - *z[0])
+			best.HeightAboveM = &h
+		}
+	}
+	return best, nil
+}
+
+// nearestInLayer returns the nearest water body in one layer and the
+// position of its nearest point in the local frame.
+func nearestInLayer(ctx context.Context, cache *Cache, layer waterLayer, frame localFrame, lat, lon float64) (*Watercourse, float64, float64, error) {
+	params := url.Values{
+		"geometry":       {fmt.Sprintf("%f,%f", lon, lat)},
+		"geometryType":   {"esriGeometryPoint"},
+		"inSR":           {"4326"},
+		"outSR":          {"4326"},
+		"spatialRel":     {"esriSpatialRelIntersects"},
+		"distance":       {fmt.Sprintf("%d", watercourseSearchM)},
+		"units":          {"esriSRUnit_Meter"},
+		"outFields":      {"*"},
+		"returnGeometry": {"true"},
+		// The offset is in the output units, degrees. A degree of
+		// latitude is the longest, so the simplification error is at
+		// most watercourseGeneralizeM in any direction.
+		"maxAllowableOffset": {strconv.FormatFloat(watercourseGeneralizeM/metersPerDegreeLat, 'f', 7, 64)},
+		"geometryPrecision":  {"6"},
+		"f":                  {"json"},
+	}
+	data, err := cachedGet(ctx, cache, layer.URL+"?"+params.Encode(), nveCacheTTL)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("%s layer: %w", layer.Kind, err)
+	}
+
+	var result waterResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, 0, 0, fmt.Errorf("%s layer decode: %w", layer.Kind, err)
+	}
+
+	best := math.Inf(1)
+	var bestE, bestN float64
+	var bestName string
+	for _, f := range result.Features {
+		if f.Geometry == nil {
+			continue
+		}
+		for _, line := range append(f.Geometry.Paths, f.Geometry.Rings...) {
+			for i := 1; i < len(line); i++ {
+				ax, ay := frame.toMeters(line[i-1][1], line[i-1][0])
+				bx, by := frame.toMeters(line[i][1], line[i][0])
+				px, py := closestOnSegment(ax, ay, bx, by)
+				if d := math.Hypot(px, py); d < best {
+					best, bestE, bestN = d, px, py
+					bestName = attrString(f.Attributes, layer.NameFields...)
+				}
+			}
+		}
+	}
+	if math.IsInf(best, 1) || best > watercourseSearchM {
+		return nil, 0, 0, nil
+	}
+
+	return &Watercourse{
+		Kind:      layer.Kind,
+		Name:      strings.TrimSpace(bestName),
+		DistanceM: int(best),
+	}, bestE, bestN, nil
+}
+
+// closestOnSegment returns the point on segment AB closest to the origin.
+func closestOnSegment(ax, ay, bx, by float64) (float64, float64) {
+	dx, dy := bx-ax, by-ay
+	lenSq := dx*dx + dy*dy
+	if lenSq == 0 {
+		return ax, ay
+	}
+	t := -(ax*dx + ay*dy) / lenSq
+	t = max(0, min(1, t))
+	return ax + t*dx, ay + t*dy
+}
+
+// applyTerrain refines awareness-map scores for slope-driven hazards using
+// the terrain around the address: flat surroundings make landslides, rock
+// fall and avalanches unlikely to start or reach the address, while steep,
+// rising ground above it makes them more likely.
+func applyTerrain(hazards []HazardResult, t *Terrain, loc localizer) {
+	if t == nil {
+		return
+	}
+
+	flat := t.MaxSlopeDeg < flatSlopeDeg && t.MaxRiseM < flatRiseM
+	steep := t.MaxSlopeDeg >= steepSlopeDeg && t.MaxRiseM >= steepRiseM
+
+	for i := range hazards {
+		h := &hazards[i]
+		switch h.ID {
+		case "landslide", "rock_fall", "avalanche":
+		default:
+			continue
+		}
+		if h.Error != "" || h.Score == 0 {
+			continue
+		}
+
+		switch {
+		case flat:
+			h.Score = int(float64(h.Score)*terrainFlatFactor + 0.5)
+			h.Details += loc.Sprintf(" Terrenget innen 100 m er flatt (maks %.0f° helning), noe som reduserer faren.", t.MaxSlopeDeg)
+		case steep:
+			h.Score = min(100, h.Score+terrainSteepBonus)
+			h.Details += loc.Sprintf(" Terrenget stiger %.0f m innen 100 m med opptil %.0f° helning.", t.MaxRiseM, t.MaxSlopeDeg)
+		}
+		h.Level = scoreLevel(h.Score)
+	}
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
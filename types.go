@@ -1,14 +1,16 @@
 package main
 
+import "encoding/json"
+
 // Address represents a geocoded Norwegian address from Kartverket.
 type Address struct {
-	Text           string  `json:"text"`
-	Latitude       float64 `json:"latitude"`
-	Longitude      float64 `json:"longitude"`
-	Kommunenummer  string  `json:"kommunenummer"`
-	Kommunenavn    string  `json:"kommunenavn"`
-	Postnummer     string  `json:"postnummer"`
-	Poststed       string  `json:"poststed"`
+	Text          string  `json:"text"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	Kommunenummer string  `json:"kommunenummer"`
+	Kommunenavn   string  `json:"kommunenavn"`
+	Postnummer    string  `json:"postnummer"`
+	Poststed      string  `json:"poststed"`
 }
 
 // HazardResult holds the outcome of a single hazard check.
@@ -16,15 +18,70 @@ type HazardResult struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Score       int    `json:"score"`     // 0-100
-	Level       string `json:"level"`     // low, medium, high, very_high
-	Details     string `json:"details"`   // Norwegian human-readable detail
+	Score       int    `json:"score"`   // 0-100
+	Level       string `json:"level"`   // low, medium, high, very_high
+	Details     string `json:"details"` // Norwegian human-readable detail
 	Error       string `json:"error,omitempty"`
+	ErrorCode   string `json:"error_code,omitempty"` // machine-readable, e.g. timeout
+
+	// Coverage tells whether NVE has mapped the area: mapped, not_mapped,
+	// awareness_only, or estimated for results NVE does not map at all. A
+	// zero score for an unmapped area means unknown.
+	Coverage string `json:"coverage,omitempty"`
+
+	// Estimated is set for results derived from terrain data rather than an
+	// NVE map.
+	Estimated bool `json:"estimated,omitempty"`
+
+	// Attributes of the matched NVE zone, when the layer provides them.
+	ZoneType     string   `json:"zone_type,omitempty"` // utløsningsområde or utløpsområde
+	FloodDepth   *float64 `json:"flood_depth_m,omitempty"`
+	ReturnPeriod int      `json:"return_period,omitempty"` // years
+	MappedDate   string   `json:"mapped_date,omitempty"`   // YYYY-MM-DD
+
+	// FailedSafetyClasses lists the TEK17 safety classes (S1-S3) the address
+	// does not satisfy, for landslide hazard zones.
+	FailedSafetyClasses []string `json:"failed_safety_classes,omitempty"`
+
+	// StormSurgeScenarios lists the municipality's storm surge scenarios
+	// with affected building counts.
+	StormSurgeScenarios []StormSurgeScenario `json:"storm_surge_scenarios,omitempty"`
+
+	// Projected is set when a climate scenario was requested and the hazard
+	// has a projection for it.
+	Projected *HazardProjection `json:"projected,omitempty"`
 }
 
-// HistoricalEvent represents a past landslide event from NVE's NSDB.
+// HazardProjection is a hazard's projected outcome under a climate scenario,
+// reported side by side with the present-day score.
+type HazardProjection struct {
+	Scenario    string `json:"scenario"`
+	Score       int    `json:"score"`
+	Level       string `json:"level"`
+	Description string `json:"description"`
+	Details     string `json:"details,omitempty"`
+}
+
+// ScenarioProjection is the overall risk under a climate scenario.
+type ScenarioProjection struct {
+	Scenario     string `json:"scenario"`
+	OverallScore int    `json:"overall_score"`
+	OverallLevel string `json:"overall_level"`
+
+	// The inputs are national and county approximations, not values for
+	// the municipality; Note says so to the reader.
+	SeaLevelRiseM     float64 `json:"sea_level_rise_m"`
+	FloodSurchargePct float64 `json:"flood_surcharge_pct"`
+	Note              string  `json:"note"`
+}
+
+// HistoricalEvent represents a past landslide, quick clay slide or flood
+// from one of NVE's event registries.
 type HistoricalEvent struct {
+	ID             string  `json:"id,omitempty"` // skredID in NSDB
+	Source         string  `json:"source"`       // skredhendelser, flomhendelser or kvikkleireskred
 	Type           string  `json:"type"`
+	TypeCode       int     `json:"type_code,omitempty"` // key in skredTypeNames
 	Date           string  `json:"date,omitempty"`
 	Location       string  `json:"location"`
 	BuildingDamage bool    `json:"building_damage"`
@@ -34,27 +91,281 @@ type HistoricalEvent struct {
 	Latitude       float64 `json:"latitude"`
 	Longitude      float64 `json:"longitude"`
 	DistanceMeters int     `json:"distance_m"`
+
+	// Scoring breakdown: the event's elevation and position relative to the
+	// address, its relevance (whether the address is in the aktsomhet zone
+	// for its type), the resulting weight, weighted score (0-100) and points
+	// contributed to the historical hazard score.
+	Elevation     *float64 `json:"elevation,omitempty"`
+	ElevationDiff *float64 `json:"elevation_diff_m,omitempty"`
+	Position      string   `json:"position,omitempty"` // upslope, level, downslope
+	Relevance     string   `json:"relevance,omitempty"`
+	Weight        float64  `json:"weight,omitempty"`
+	Score         int      `json:"score,omitempty"`
+	Contribution  float64  `json:"contribution,omitempty"`
+}
+
+// EventsResponse is one page of historical events around a point.
+type EventsResponse struct {
+	Events  []HistoricalEvent `json:"events"`
+	Offset  int               `json:"offset"`
+	Limit   int               `json:"limit"`
+	HasMore bool              `json:"has_more"`
+}
+
+// HistoricalSummary aggregates historical events for an area.
+type HistoricalSummary struct {
+	TotalEvents    int            `json:"total_events"`
+	DatedEvents    int            `json:"dated_events"`
+	FirstEvent     string         `json:"first_event,omitempty"`
+	LastEvent      string         `json:"last_event,omitempty"`
+	ObservedYears  float64        `json:"observed_years,omitempty"`
+	ByType         []TypeStats    `json:"by_type"`
+	ByDecade       map[string]int `json:"by_decade"` // e.g. "1950s"
+	ByMonth        []int          `json:"by_month"`  // January first
+	BySeason       map[string]int `json:"by_season"` // winter, spring, summer, autumn
+	BuildingDamage int            `json:"building_damage"`
+	RoadDamage     int            `json:"road_damage"`
+	Fatalities     int            `json:"fatalities"`
+
+	// ReturnIntervalYears is the mean time between dated events over the
+	// observed period; ProbabilityInHorizon the chance of at least one event
+	// in HorizonYears. Both assume a complete record and are lower bounds.
+	ReturnIntervalYears  *float64 `json:"return_interval_years,omitempty"`
+	ProbabilityInHorizon *float64 `json:"probability_in_horizon,omitempty"`
+	HorizonYears         int      `json:"horizon_years"`
+
+	// Truncated is set when the event search hit its page limit, so the
+	// counts may be incomplete.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
-// WeatherAlert represents an active MET weather warning.
+// TypeStats aggregates historical events of one skred type.
+type TypeStats struct {
+	Type                string   `json:"type"`
+	TypeCode            int      `json:"type_code,omitempty"`
+	Count               int      `json:"count"`
+	BuildingDamage      int      `json:"building_damage"`
+	Fatalities          int      `json:"fatalities"`
+	LastEvent           string   `json:"last_event,omitempty"`
+	ReturnIntervalYears *float64 `json:"return_interval_years,omitempty"`
+
+	dated int
+}
+
+// WeatherAlert represents an active or upcoming MET weather warning.
 type WeatherAlert struct {
 	Event       string `json:"event"`
 	Severity    string `json:"severity"`
 	Description string `json:"description"`
 	Instruction string `json:"instruction"`
 	Area        string `json:"area"`
+
+	Onset          string `json:"onset,omitempty"`           // RFC 3339
+	Expires        string `json:"expires,omitempty"`         // RFC 3339
+	AwarenessLevel string `json:"awareness_level,omitempty"` // e.g. "2; yellow; Moderate"
+	AwarenessType  string `json:"awareness_type,omitempty"`  // e.g. "1; Wind"
+	Certainty      string `json:"certainty,omitempty"`
+	Consequences   string `json:"consequences,omitempty"`
+
+	// Geometry is the alert area as a GeoJSON geometry.
+	Geometry json.RawMessage `json:"geometry,omitempty"`
+}
+
+// VarsomWarning is one day of an NVE flood, landslide or avalanche warning
+// from Varsom.
+type VarsomWarning struct {
+	Type      string `json:"type"`  // flood, landslide, avalanche
+	Level     int    `json:"level"` // 1-4 (flood, landslide) or 1-5 (avalanche)
+	ValidFrom string `json:"valid_from"`
+	ValidTo   string `json:"valid_to"`
+	Region    string `json:"region,omitempty"`
+	MainText  string `json:"main_text,omitempty"`
+}
+
+// CurrentRisk is the time-varying risk: the static hazards escalated by
+// warnings that are active right now.
+type CurrentRisk struct {
+	Score       int                `json:"score"`
+	Level       string             `json:"level"`
+	Summary     string             `json:"summary"`
+	Escalations []HazardEscalation `json:"escalations,omitempty"`
+}
+
+// HazardEscalation records a static hazard raised by an active warning.
+type HazardEscalation struct {
+	HazardID     string `json:"hazard_id"`
+	Source       string `json:"source"` // MET or Varsom
+	Event        string `json:"event"`
+	Severity     string `json:"severity"`
+	BaseScore    int    `json:"base_score"`
+	CurrentScore int    `json:"current_score"`
+}
+
+// Terrain describes the ground around the address, sampled from Kartverket
+// høydedata.
+type Terrain struct {
+	SlopeDeg  float64  `json:"slope_deg"`            // local slope at the address
+	AspectDeg *float64 `json:"aspect_deg,omitempty"` // direction the slope faces, 0 = north
+	Aspect    string   `json:"aspect,omitempty"`     // N, NØ, Ø, ...
+
+	// MaxSlopeDeg and MaxRiseM describe the steepest gradient and the highest
+	// ground relative to the address within 100 m.
+	MaxSlopeDeg float64 `json:"max_slope_deg"`
+	MaxRiseM    float64 `json:"max_rise_m"`
+}
+
+// Watercourse is the nearest river or stream to the address.
+type Watercourse struct {
+	Kind         string   `json:"kind"` // river or lake
+	Name         string   `json:"name,omitempty"`
+	DistanceM    int      `json:"distance_m"`
+	HeightAboveM *float64 `json:"height_above_m,omitempty"`
+}
+
+// ElevationSource describes the elevation data used for the address.
+type ElevationSource struct {
+	Source       string  `json:"source"` // høydedata datakilde, e.g. dtm1
+	ResolutionM  float64 `json:"resolution_m,omitempty"`
+	Datum        string  `json:"datum"` // NN2000
+	UncertaintyM float64 `json:"uncertainty_m"`
+}
+
+// BuildingAssessment is the worst case over the corners and centroid of a
+// building footprint at the address.
+type BuildingAssessment struct {
+	ID        string          `json:"id,omitempty"` // bygningsnummer
+	Footprint [][2]float64    `json:"footprint"`    // exterior ring as [lat, lon]
+	Points    []BuildingPoint `json:"points"`
+
+	// Hazards holds the highest-scoring result per hazard over all points.
+	Hazards      []HazardResult `json:"hazards"`
+	OverallScore int            `json:"overall_score"`
+	OverallLevel string         `json:"overall_level"`
+
+	// LowestElevation is the ground elevation at the lowest point, a proxy
+	// for the lowest floor level.
+	LowestElevation *float64 `json:"lowest_elevation,omitempty"`
+}
+
+// BuildingPoint is one sampled point of a building footprint.
+type BuildingPoint struct {
+	Kind      string   `json:"kind"` // corner or centroid
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Elevation *float64 `json:"elevation,omitempty"`
+	MaxScore  int      `json:"max_score"`
+
+	east, north float64 // position in the address's local frame
 }
 
 // RiskResponse is the full response for a risk assessment.
 type RiskResponse struct {
-	Address       Address        `json:"address"`
-	OverallScore  int            `json:"overall_score"`
-	OverallLevel  string         `json:"overall_level"`
-	Summary       string         `json:"summary"`
-	Elevation     *float64       `json:"elevation,omitempty"`
-	Hazards          []HazardResult  `json:"hazards"`
-	WeatherAlerts    []WeatherAlert  `json:"weather_alerts"`
-	HistoricalEvents []HistoricalEvent `json:"historical_events,omitempty"`
+	Address          Address              `json:"address"`
+	OverallScore     int                  `json:"overall_score"`
+	OverallLevel     string               `json:"overall_level"`
+	Summary          string               `json:"summary"`
+	Elevation        *float64             `json:"elevation,omitempty"`
+	ElevationSource  *ElevationSource     `json:"elevation_source,omitempty"`
+	Hazards          []HazardResult       `json:"hazards"`
+	WeatherAlerts    []WeatherAlert       `json:"weather_alerts"`
+	VarsomWarnings   []VarsomWarning      `json:"varsom_warnings"`
+	HistoricalEvents []HistoricalEvent    `json:"historical_events,omitempty"`
+	Projection       *ScenarioProjection  `json:"projection,omitempty"`
+	CurrentRisk      *CurrentRisk         `json:"current_risk"`
+	Terrain          *Terrain             `json:"terrain,omitempty"`
+	Watercourse      *Watercourse         `json:"watercourse,omitempty"`
+	Buildings        []BuildingAssessment `json:"buildings,omitempty"`
+	BuildingsError   string               `json:"buildings_error,omitempty"`
+
+	// HistoricalEventsTruncated is set when the event search hit its page
+	// limit, so historical_events and its score may be incomplete.
+	HistoricalEventsTruncated bool               `json:"historical_events_truncated,omitempty"`
+	HistoricalSummary         *HistoricalSummary `json:"historical_summary,omitempty"`
+
+	// Sources lists the upstream services the assessment is based on.
+	Sources     []DataSource `json:"sources"`
+	GeneratedAt string       `json:"generated_at"` // RFC 3339
+
+	// AssessmentID identifies the stored snapshot, when storage is enabled.
+	AssessmentID string `json:"assessment_id,omitempty"`
+}
+
+// DataSource is an upstream service used for an assessment.
+type DataSource struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	RetrievedAt string `json:"retrieved_at"` // RFC 3339, oldest response used
+}
+
+// AssessmentSnapshot is a stored assessment with the upstream responses it
+// was based on.
+type AssessmentSnapshot struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"created_at"` // RFC 3339
+	// Request is the query string that reproduces the assessment.
+	Request  string             `json:"request"`
+	Response RiskResponse       `json:"response"`
+	Upstream []UpstreamResponse `json:"upstream"`
+}
+
+// UpstreamResponse identifies one raw upstream response by its hash.
+type UpstreamResponse struct {
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	RetrievedAt string `json:"retrieved_at"`     // RFC 3339
+	SHA256      string `json:"sha256,omitempty"` // absent for a 404 or a failure
+	Bytes       int    `json:"bytes"`
+	Error       string `json:"error,omitempty"` // why the fetch failed
+}
+
+// KommuneRisk aggregates hazard exposure for a whole municipality.
+type KommuneRisk struct {
+	Kommunenummer string  `json:"kommunenummer"`
+	Kommunenavn   string  `json:"kommunenavn,omitempty"`
+	AreaKm2       float64 `json:"area_km2"`
+
+	// Addresses is the number of addresses in the municipality, capped by
+	// the address API.
+	Addresses          int    `json:"addresses"`
+	AddressesTruncated bool   `json:"addresses_truncated,omitempty"`
+	AddressesError     string `json:"addresses_error,omitempty"`
+
+	// Zones are attributed to the municipality by polygon centroid;
+	// ZonesNote explains the approximation to the reader.
+	Zones           []ZoneExposure       `json:"zones"`
+	ZonesNote       string               `json:"zones_note"`
+	StormSurge      []StormSurgeScenario `json:"storm_surge"`
+	StormSurgeError string               `json:"storm_surge_error,omitempty"`
+
+	// HistoricalEvents counts registered events per source. A source that
+	// could not be fetched is missing here and has its message in
+	// HistoricalEventsErrors, so it is not mistaken for zero events.
+	HistoricalEvents          map[string]int    `json:"historical_events"`
+	HistoricalEventsTruncated bool              `json:"historical_events_truncated,omitempty"`
+	HistoricalEventsErrors    map[string]string `json:"historical_events_errors,omitempty"`
+
+	GeneratedAt string `json:"generated_at"` // RFC 3339
+}
+
+// ZoneExposure is the extent of one hazard map within a municipality.
+type ZoneExposure struct {
+	HazardID  string  `json:"hazard_id"`
+	Name      string  `json:"name"`
+	AreaKm2   float64 `json:"area_km2"`
+	AreaShare float64 `json:"area_share"` // of the municipality's area, 0-1
+	Addresses int     `json:"addresses"`
+	Truncated bool    `json:"truncated,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	ErrorCode string  `json:"error_code,omitempty"`
+}
+
+// StormSurgeScenario is one storm surge scenario from Kartverket's
+// consequence data.
+type StormSurgeScenario struct {
+	Code      string `json:"code"`
+	Year      string `json:"year"`
+	Buildings int    `json:"buildings"` // affected buildings in the municipality
 }
 
 // scoreLevel returns the risk level string for a given score.
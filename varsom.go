@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NVE Varsom forecast APIs. Flood and landslide warnings are issued per
+// municipality, avalanche warnings per forecast region (looked up by point).
+const (
+	varsomFloodURL     = "https://api01.nve.no/hydrology/forecast/flood/v1.0.8/api/Warning/Municipality"
+	varsomLandslideURL = "https://api01.nve.no/hydrology/forecast/landslide/v1.0.6/api/Warning/Municipality"
+	varsomAvalancheURL = "https://api01.nve.no/hydrology/forecast/avalanche/v6.2.1/api/AvalancheWarningByCoordinates/Simple"
+)
+
+const varsomCacheTTL = 15 * time.Minute
+
+// varsomDays is how many days of warnings to fetch, today included.
+const varsomDays = 3
+
+// Varsom LangKeys. Warnings are only written in Norwegian and English.
+const (
+	varsomLangNorwegian = 1
+	varsomLangEnglish   = 2
+)
+
+// varsomLangKey returns the Varsom LangKey for a response language.
+func varsomLangKey(lang string) int {
+	if lang == langEnglish {
+		return varsomLangEnglish
+	}
+	return varsomLangNorwegian
+}
+
+// varsomMunicipalityWarning is one day of flood or landslide warning.
+type varsomMunicipalityWarning struct {
+	ActivityLevel string `json:"ActivityLevel"`
+	ValidFrom     string `json:"ValidFrom"`
+	ValidTo       string `json:"ValidTo"`
+	MainText      string `json:"MainText"`
+}
+
+// varsomAvalancheWarning is one day of avalanche warning for a region.
+type varsomAvalancheWarning struct {
+	RegionName  string `json:"RegionName"`
+	DangerLevel string `json:"DangerLevel"`
+	ValidFrom   string `json:"ValidFrom"`
+	ValidTo     string `json:"ValidTo"`
+	MainText    string `json:"MainText"`
+}
+
+// getVarsomWarnings fetches today's and the next two days' flood, landslide
+// and avalanche warnings for the address. Each source is fetched in parallel;
+// a failing source is logged and skipped. Texts are in English for English
+// responses and in Norwegian otherwise.
+func getVarsomWarnings(ctx context.Context, cache *Cache, kommunenummer string, lat, lon float64, loc localizer) []VarsomWarning {
+	langKey := varsomLangKey(loc.Lang)
+	start := time.Now()
+	end := start.AddDate(0, 0, varsomDays-1)
+
+	fetchers := []struct {
+		name  string
+		fetch func() ([]VarsomWarning, error)
+	}{
+		{"flood", func() ([]VarsomWarning, error) {
+			return fetchVarsomMunicipality(ctx, cache, varsomFloodURL, "flood", kommunenummer, langKey, start, end)
+		}},
+		{"landslide", func() ([]VarsomWarning, error) {
+			return fetchVarsomMunicipality(ctx, cache, varsomLandslideURL, "landslide", kommunenummer, langKey, start, end)
+		}},
+		{"avalanche", func() ([]VarsomWarning, error) {
+			return fetchVarsomAvalanche(ctx, cache, lat, lon, langKey, start, end)
+		}},
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		warnings []VarsomWarning
+	)
+	for _, f := range fetchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, err := f.fetch()
+			if err != nil {
+				log.Printf("varsom %s error: %v", f.name, err)
+				return
+			}
+			mu.Lock()
+			warnings = append(warnings, w...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return warnings
+}
+
+// fetchVarsomMunicipality fetches flood or landslide warnings for a municipality.
+// Level 1 (green) days are kept so the client sees the full outlook.
+func fetchVarsomMunicipality(ctx context.Context, cache *Cache, baseURL, kind, kommunenummer string, langKey int, start, end time.Time) ([]VarsomWarning, error) {
+	u := fmt.Sprintf("%s/%s/%d/%s/%s", baseURL, kommunenummer, langKey,
+		start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	data, err := cachedGet(ctx, cache, u, varsomCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("varsom %s: %w", kind, err)
+	}
+
+	var result []varsomMunicipalityWarning
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("varsom %s decode: %w", kind, err)
+	}
+
+	warnings := make([]VarsomWarning, 0, len(result))
+	for _, r := range result {
+		level, err := strconv.Atoi(r.ActivityLevel)
+		if err != nil {
+			continue
+		}
+		warnings = append(warnings, VarsomWarning{
+			Type:      kind,
+			Level:     level,
+			ValidFrom: r.ValidFrom,
+			ValidTo:   r.ValidTo,
+			MainText:  r.MainText,
+		})
+	}
+	return warnings, nil
+}
+
+// fetchVarsomAvalanche fetches avalanche warnings for the forecast region
+// containing the point. Points outside all regions return an empty list.
+func fetchVarsomAvalanche(ctx context.Context, cache *Cache, lat, lon float64, langKey int, start, end time.Time) ([]VarsomWarning, error) {
+	u := fmt.Sprintf("%s/%f/%f/%d/%s/%s", varsomAvalancheURL, lat, lon, langKey,
+		start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	data, err := cachedGet(ctx, cache, u, varsomCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("varsom avalanche: %w", err)
+	}
+
+	var result []varsomAvalancheWarning
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("varsom avalanche decode: %w", err)
+	}
+
+	warnings := make([]VarsomWarning, 0, len(result))
+	for _, r := range result {
+		level, err := strconv.Atoi(r.DangerLevel)
+		if err != nil || level == 0 {
+			// 0 means no rating (outside the season or region).
+			continue
+		}
+		warnings = append(warnings, VarsomWarning{
+			Type:      "avalanche",
+			Level:     level,
+			ValidFrom: r.ValidFrom,
+			ValidTo:   r.ValidTo,
+			Region:    r.RegionName,
+			MainText:  r.MainText,
+		})
+	}
+	return warnings, nil
+}
+
+// varsomAlertEquivalent converts a Varsom warning that is valid at now into
+// the MetAlerts event and severity it corresponds to, so it can escalate
+// static hazards the same way. Green levels and other days return false.
+func varsomAlertEquivalent(w VarsomWarning, now time.Time) (WeatherAlert, bool) {
+	if !varsomValidAt(w, now) {
+		return WeatherAlert{}, false
+	}
+
+	// Flood and landslide use levels 1-4 (green to red); avalanche uses the
+	// European danger scale 1-5 where 3 (considerable) is the first to act on.
+	level := w.Level
+	event := w.Type
+	if w.Type == "avalanche" {
+		level--
+		event = "avalanches"
+	}
+
+	var severity string
+	switch {
+	case level >= 4:
+		severity = "Extreme"
+	case level == 3:
+		severity = "Severe"
+	case level == 2:
+		severity = "Moderate"
+	default:
+		return WeatherAlert{}, false
+	}
+	return WeatherAlert{Event: event, Severity: severity}, true
+}
+
+// varsomValidAt reports whether the warning's validity period covers t.
+// Varsom timestamps are local Norwegian time without offset.
+func varsomValidAt(w VarsomWarning, t time.Time) bool {
+	const layout = "2006-01-02T15:04:05"
+	from, err := time.ParseInLocation(layout, w.ValidFrom, osloLocation)
+	if err != nil {
+		return false
+	}
+	to, err := time.ParseInLocation(layout, w.ValidTo, osloLocation)
+	if err != nil {
+		return false
+	}
+	return !t.Before(from) && t.Before(to)
+}
+
+// osloLocation falls back to a fixed CET offset when tzdata is unavailable
+// (e.g. minimal container images).
+var osloLocation = func() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Oslo"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CET", 1*60*60)
+}()